@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"scrapyuk-backend/config"
 	"scrapyuk-backend/internal/handlers"
+	"scrapyuk-backend/internal/jobs"
 	"scrapyuk-backend/internal/middleware"
+	"scrapyuk-backend/internal/scheduler"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -41,15 +49,35 @@ func main() {
 	// Seed database (only if empty)
 	config.SeedDatabase()
 
-	// Initialize MinIO
-	config.InitMinIO()
-	if config.IsMinIOAvailable() {
-		config.SetupMinIOPolicy()
+	// Initialize authentication
+	config.InitAuth()
+
+	// Initialize quote pricing
+	config.InitPricing()
+
+	// Initialize asset storage (MinIO or local filesystem)
+	config.InitStorage()
+
+	// Set up background maintenance jobs
+	var jobScheduler *scheduler.Scheduler
+	if jobsConfig := config.LoadJobsConfig(); jobsConfig.Enabled {
+		jobScheduler = scheduler.New(jobs.NewDBLocker(config.GetDB()))
+		if err := jobs.Register(jobScheduler, jobsConfig); err != nil {
+			log.Fatal("Failed to register background jobs:", err)
+		}
+	} else {
+		log.Println("Background jobs disabled")
 	}
 
 	// Initialize Gin router
 	router := gin.New()
 
+	// Only believe forwarded client IPs from our own proxies; shared link
+	// network restrictions and analytics depend on them
+	if err := router.SetTrustedProxies(config.GetTrustedProxies()); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// Add middleware
 	router.Use(middleware.Logger())
 	router.Use(middleware.ErrorHandler())
@@ -58,51 +86,126 @@ func main() {
 	router.Use(middleware.SecurityHeaders())
 
 	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
+	healthHandler := handlers.NewHealthHandler(jobScheduler)
+	authHandler := handlers.NewAuthHandler()
 	projectHandler := handlers.NewProjectHandler()
 	assetHandler := handlers.NewAssetHandler()
+	objectHandler := handlers.NewObjectHandler()
+	revisionHandler := handlers.NewRevisionHandler()
 	sharedLinkHandler := handlers.NewSharedLinkHandler()
+	commentHandler := handlers.NewCommentHandler()
+	frameTemplateHandler := handlers.NewFrameTemplateHandler()
+	priceRateHandler := handlers.NewPriceRateHandler()
 
 	// Health check routes
 	router.GET("/health", healthHandler.HealthCheck)
 	router.GET("/health/detailed", healthHandler.DetailedHealthCheck)
 
 	// API routes
+	requireAuth := middleware.RequireAuth()
+	requireAdmin := middleware.RequireAdmin()
+
 	api := router.Group("/api")
 	{
+		// Auth routes
+		authRoutes := api.Group("/auth")
+		{
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/logout", requireAuth, authHandler.Logout)
+			authRoutes.GET("/me", requireAuth, authHandler.Me)
+		}
+
 		// Project routes
-		projects := api.Group("/projects")
+		projects := api.Group("/projects", requireAuth)
 		{
 			projects.GET("", projectHandler.GetProjects)
 			projects.POST("", projectHandler.CreateProject)
+			projects.POST("/import", projectHandler.ImportProject)
 			projects.GET("/:id", projectHandler.GetProject)
 			projects.PUT("/:id", projectHandler.UpdateProject)
+			projects.PATCH("/:id", projectHandler.PatchProject)
 			projects.DELETE("/:id", projectHandler.DeleteProject)
+			projects.POST("/:id/duplicate", projectHandler.DuplicateProject)
+			projects.POST("/:id/as-template", projectHandler.SaveProjectAsTemplate)
+			projects.GET("/:id/export", projectHandler.ExportProject)
+			projects.GET("/:id/export.glb", projectHandler.ExportGLB)
+			projects.GET("/:id/production-export", projectHandler.ExportProduction)
+			projects.GET("/:id/quote", projectHandler.GetProjectQuote)
+			projects.GET("/:id/cut-sheets", projectHandler.GetCutSheets)
 
 			// Project asset routes
 			projects.GET("/:id/assets", assetHandler.GetProjectAssets)
 			projects.POST("/:id/assets", assetHandler.UploadAsset)
+			projects.GET("/:id/assets/:assetId/url", assetHandler.GetAssetURL)
+
+			// Project revision history routes
+			projects.GET("/:id/revisions", revisionHandler.GetRevisions)
+			projects.GET("/:id/revisions/diff", revisionHandler.DiffRevisions)
+			projects.GET("/:id/revisions/:number", revisionHandler.GetRevision)
+			projects.POST("/:id/revisions/:number/restore", revisionHandler.RestoreRevision)
+
+			// Project scene object routes
+			projects.GET("/:id/objects", objectHandler.GetProjectObjects)
+			projects.POST("/:id/objects", objectHandler.CreateObject)
+			projects.PATCH("/:id/objects", objectHandler.BatchUpdateObjects)
+			projects.POST("/:id/objects/reorder", objectHandler.ReorderObjects)
+			projects.PUT("/:id/objects/:objectId", objectHandler.UpdateObject)
+			projects.DELETE("/:id/objects/:objectId", objectHandler.DeleteObject)
+
+			// Comment routes
+			projects.GET("/:id/comments", commentHandler.GetProjectComments)
+			projects.POST("/:id/comments/:commentId/replies", commentHandler.ReplyToComment)
+			projects.POST("/:id/comments/:commentId/resolve", commentHandler.ResolveComment)
+			projects.POST("/:id/comments/:commentId/reopen", commentHandler.ReopenComment)
 
 			// Project shared links routes
 			projects.GET("/:id/shared-links", sharedLinkHandler.GetProjectSharedLinks)
+			projects.GET("/:id/decisions", sharedLinkHandler.GetProjectDecisions)
+		}
+
+		// Frame template routes
+		frameTemplates := api.Group("/frame-templates", requireAuth)
+		{
+			frameTemplates.GET("", frameTemplateHandler.GetFrameTemplates)
+			frameTemplates.POST("", requireAdmin, frameTemplateHandler.CreateFrameTemplate)
+			frameTemplates.GET("/:id", frameTemplateHandler.GetFrameTemplate)
+			frameTemplates.PUT("/:id", requireAdmin, frameTemplateHandler.UpdateFrameTemplate)
+			frameTemplates.DELETE("/:id", requireAdmin, frameTemplateHandler.DeleteFrameTemplate)
+		}
+
+		// Price table routes
+		priceRates := api.Group("/price-rates", requireAuth)
+		{
+			priceRates.GET("", priceRateHandler.GetPriceRates)
+			priceRates.PUT("/:key", requireAdmin, priceRateHandler.UpdatePriceRate)
 		}
 
 		// Asset routes
 		assets := api.Group("/assets")
 		{
-			assets.DELETE("/:id", assetHandler.DeleteAsset)
-			assets.GET("/*filepath", assetHandler.ServeAsset)
+			assets.DELETE("/:id", requireAuth, assetHandler.DeleteAsset)
+			// Asset files are served to the owning creator's session, to a
+			// shared link for the asset's project (?share= or X-Share-Token)
+			// and through signed URLs. The handler also serves
+			// /:id/cutline.svg and .dxf to the owner.
+			assets.GET("/*filepath", middleware.OptionalAuth(), assetHandler.ServeAsset)
 		}
 
 		// Shared link routes
-		sharedLinks := api.Group("/shared-links")
+		sharedLinks := api.Group("/shared-links", requireAuth)
 		{
 			sharedLinks.POST("", sharedLinkHandler.CreateSharedLink)
 			sharedLinks.DELETE("/:token", sharedLinkHandler.DeleteSharedLink)
+			sharedLinks.GET("/:token/analytics", sharedLinkHandler.GetSharedLinkAnalytics)
 		}
 
 		// Shared project routes (buyer view)
 		api.GET("/shared/:token", sharedLinkHandler.GetSharedProject)
+		api.POST("/shared/:token/unlock", sharedLinkHandler.UnlockSharedLink)
+		api.POST("/shared/:token/decision", sharedLinkHandler.DecideSharedLink)
+		api.GET("/shared/:token/assets/:ref", sharedLinkHandler.GetSharedAsset)
+		api.GET("/shared/:token/comments", commentHandler.GetBuyerComments)
+		api.POST("/shared/:token/comments", commentHandler.CreateBuyerComment)
 	}
 
 	// API documentation route (simple endpoint list)
@@ -115,31 +218,86 @@ func main() {
 					"GET /health":          "Basic health check",
 					"GET /health/detailed": "Detailed health check with database and storage status",
 				},
+				"auth": map[string]string{
+					"POST /api/auth/login":  "Log in as a creator and receive a session token",
+					"POST /api/auth/logout": "Revoke the current session",
+					"GET /api/auth/me":      "Get the authenticated creator",
+				},
 				"projects": map[string]string{
-					"GET /api/projects":                  "List all projects (paginated)",
-					"POST /api/projects":                 "Create a new project",
-					"GET /api/projects/:id":              "Get project by ID",
-					"PUT /api/projects/:id":              "Update project by ID",
-					"DELETE /api/projects/:id":           "Delete project by ID",
-					"GET /api/projects/:id/assets":       "List project assets",
-					"POST /api/projects/:id/assets":      "Upload asset to project",
-					"GET /api/projects/:id/shared-links": "List project shared links",
+					"GET /api/projects":                         "List all projects (paginated, ?templates=true for starter designs)",
+					"POST /api/projects":                        "Create a new project (optionally from a starter design via template_id)",
+					"GET /api/projects/:id":                     "Get project by ID",
+					"PUT /api/projects/:id":                     "Update project by ID",
+					"PATCH /api/projects/:id":                   "Patch project data (JSON Patch or merge patch)",
+					"DELETE /api/projects/:id":                  "Delete project by ID",
+					"POST /api/projects/:id/duplicate":          "Deep-copy a project with its objects and asset files",
+					"POST /api/projects/:id/as-template":        "Save a copy of a project as a starter design",
+					"GET /api/projects/:id/export":              "Download a ZIP archive with a manifest and the asset PNGs",
+					"GET /api/projects/:id/export.glb":          "Download the assembled scene as a binary glTF model",
+					"GET /api/projects/:id/production-export":   "Download print-ready layer PNGs (?dpi=, default 300) and a cutting sheet",
+					"GET /api/projects/:id/quote":               "Calculate the bill of materials and price of a project",
+					"GET /api/projects/:id/cut-sheets":          "Nest the layers onto paper sheets (?size=a4|a3|letter|custom, ?format=json|svg|pdf)",
+					"POST /api/projects/import":                 "Recreate a project from an exported archive (multipart \"file\")",
+					"GET /api/projects/:id/assets":              "List project assets",
+					"POST /api/projects/:id/assets":             "Upload asset to project",
+					"GET /api/projects/:id/assets/:assetId/url": "Issue a time-limited signed URL for an asset",
+					"GET /api/projects/:id/shared-links":        "List project shared links",
+					"GET /api/projects/:id/decisions":           "List buyer approval decisions",
+				},
+				"revisions": map[string]string{
+					"GET /api/projects/:id/revisions":                  "List project revisions (newest first)",
+					"GET /api/projects/:id/revisions/diff":             "Compare two revisions (?from=&to=)",
+					"GET /api/projects/:id/revisions/:number":          "Get a revision snapshot",
+					"POST /api/projects/:id/revisions/:number/restore": "Restore a revision as the new head",
+				},
+				"objects": map[string]string{
+					"GET /api/projects/:id/objects":              "List scene objects in render order",
+					"POST /api/projects/:id/objects":             "Create a scene object",
+					"PATCH /api/projects/:id/objects":            "Update several scene objects atomically",
+					"POST /api/projects/:id/objects/reorder":     "Set the render order of all scene objects",
+					"PUT /api/projects/:id/objects/:objectId":    "Update a scene object",
+					"DELETE /api/projects/:id/objects/:objectId": "Delete a scene object",
+				},
+				"frame_templates": map[string]string{
+					"GET /api/frame-templates":        "List frame templates",
+					"POST /api/frame-templates":       "Create a frame template (admin only)",
+					"GET /api/frame-templates/:id":    "Get frame template by ID",
+					"PUT /api/frame-templates/:id":    "Update a frame template (admin only; rejected if existing projects would no longer fit)",
+					"DELETE /api/frame-templates/:id": "Delete a frame template no project uses (admin only)",
+				},
+				"price_rates": map[string]string{
+					"GET /api/price-rates":      "List the prices quotes are calculated from",
+					"PUT /api/price-rates/:key": "Change a price (admins only)",
 				},
 				"assets": map[string]string{
-					"DELETE /api/assets/:id":    "Delete asset by ID",
-					"GET /api/assets/*filepath": "Serve asset file",
+					"DELETE /api/assets/:id":          "Delete asset by ID",
+					"GET /api/assets/:id/cutline.svg": "Trace the asset's outline for a cutting plotter (?offset_cm=, ?kerf_cm=, ?tolerance_cm=)",
+					"GET /api/assets/:id/cutline.dxf": "Trace the asset's outline as a DXF drawing",
+					"GET /api/assets/*filepath":       "Serve asset file (owner session, ?share= token or signed URL)",
+				},
+				"comments": map[string]string{
+					"GET /api/projects/:id/comments":                     "List comment threads (?status=open|resolved)",
+					"POST /api/projects/:id/comments/:commentId/replies": "Reply to a comment thread",
+					"POST /api/projects/:id/comments/:commentId/resolve": "Resolve a comment thread",
+					"POST /api/projects/:id/comments/:commentId/reopen":  "Reopen a resolved comment thread",
+					"GET /api/shared/:token/comments":                    "List comment threads left through a shared link",
+					"POST /api/shared/:token/comments":                   "Leave buyer feedback, optionally pinned to an object or position",
 				},
 				"shared_links": map[string]string{
-					"POST /api/shared-links":          "Create shared link for project",
-					"DELETE /api/shared-links/:token": "Delete shared link by token",
-					"GET /api/shared/:token":          "Get shared project by token (buyer view)",
+					"POST /api/shared-links":                 "Create shared link for project",
+					"DELETE /api/shared-links/:token":        "Delete shared link by token",
+					"GET /api/shared-links/:token/analytics": "View counts, unique viewers and access time series",
+					"GET /api/shared/:token":                 "Get shared project by token (buyer view)",
+					"POST /api/shared/:token/decision":       "Approve the shared project or request changes",
+					"POST /api/shared/:token/unlock":         "Unlock a password- or email-restricted shared link",
 				},
 			},
 			"notes": []string{
 				"All endpoints return JSON responses",
+				"Creator endpoints require a session token (Authorization: Bearer <token> or session cookie)",
 				"File uploads accept only PNG images up to 10MB",
-				"Project frame sizes: 20x20, 20x30",
-				"Shared links can have optional expiration dates",
+				"Projects are built for a frame template (frame_template_id); object positions must fit inside the frame",
+				"Shared links can have optional expiration dates, passwords, view limits and IP/email-domain restrictions",
 				"CORS is configured for frontend integration",
 			},
 		}
@@ -150,13 +308,42 @@ func main() {
 	log.Printf("Starting ScrapYuk Backend API server on port %s", port)
 	log.Printf("Environment: %s", ginMode)
 	log.Printf("Database: %s", os.Getenv("DB_PATH"))
-	if config.IsMinIOAvailable() {
-		log.Printf("Storage: MinIO available at %s", os.Getenv("MINIO_ENDPOINT"))
+	if config.IsStorageAvailable() {
+		log.Printf("Storage: %s backend available", config.GetStorage().Name())
 	} else {
-		log.Println("Storage: MinIO not available - file uploads disabled")
+		log.Println("Storage: not available - file uploads disabled")
+	}
+
+	if jobScheduler != nil {
+		jobScheduler.Start()
+	}
+
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
 	}
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	// Wait for an interrupt, then let in-flight requests and jobs finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown error:", err)
+	}
+	if jobScheduler != nil {
+		if err := jobScheduler.Stop(shutdownCtx); err != nil {
+			log.Println("Scheduler shutdown error:", err)
+		}
 	}
 }
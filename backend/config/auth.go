@@ -0,0 +1,52 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// SessionCookieName is the cookie carrying the creator session token
+const SessionCookieName = "scrapyuk_session"
+
+// ViewerCookieName is the cookie carrying an unlocked shared link's viewer token
+const ViewerCookieName = "scrapyuk_viewer"
+
+// ViewerTokenTTL is how long an unlocked shared link stays unlocked
+const ViewerTokenTTL = time.Hour
+
+var JWTSecret []byte
+var SessionTTL time.Duration
+
+// InitAuth loads the JWT signing secret and session lifetime from the environment
+func InitAuth() {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		if os.Getenv("GIN_MODE") == "release" {
+			log.Fatal("JWT_SECRET must be set in release mode")
+		}
+		secret = "scrapyuk-dev-secret-change-me"
+		log.Println("Warning: JWT_SECRET not set, using insecure development secret")
+	}
+	JWTSecret = []byte(secret)
+
+	SessionTTL = 24 * time.Hour
+	if ttl := os.Getenv("SESSION_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil || parsed <= 0 {
+			log.Printf("Invalid SESSION_TTL %q, using default of %s", ttl, SessionTTL)
+		} else {
+			SessionTTL = parsed
+		}
+	}
+}
+
+// GetJWTSecret returns the secret used to sign session tokens
+func GetJWTSecret() []byte {
+	return JWTSecret
+}
+
+// GetSessionTTL returns how long issued session tokens stay valid
+func GetSessionTTL() time.Duration {
+	return SessionTTL
+}
@@ -5,8 +5,11 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"scrapyuk-backend/internal/auth"
 	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/pricing"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -67,22 +70,87 @@ func RunMigrations() {
 
 	// Auto-migrate all models
 	err := DB.AutoMigrate(
+		&models.User{},
+		&models.Session{},
+		&models.FrameTemplate{},
 		&models.Project{},
+		&models.ProjectRevision{},
 		&models.Asset{},
+		&models.AssetRendition{},
 		&models.Object{},
 		&models.SharedLink{},
+		&models.SharedLinkAccess{},
+		&models.Comment{},
+		&models.ApprovalDecision{},
+		&models.PriceRate{},
+		&models.JobLock{},
 	)
 	if err != nil {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	backfillRevisions()
+
 	log.Println("Migrations completed successfully")
 }
 
+// backfillRevisions records a baseline revision of the current version of every
+// project that has none, such as projects saved before revision history
+// existed, so that version can be compared with and restored later
+func backfillRevisions() {
+	var projects []models.Project
+	err := DB.Unscoped().
+		Where("NOT EXISTS (SELECT 1 FROM project_revisions WHERE project_revisions.project_id = projects.id AND project_revisions.number = projects.version)").
+		Preload("Objects", func(tx *gorm.DB) *gorm.DB { return tx.Order("sort_order ASC, id ASC") }).
+		Find(&projects).Error
+	if err != nil {
+		log.Printf("Failed to find projects without revisions: %v", err)
+		return
+	}
+
+	for _, project := range projects {
+		if err := DB.Create(baselineRevision(&project)).Error; err != nil {
+			log.Printf("Failed to record baseline revision of project %d: %v", project.ID, err)
+		}
+	}
+	if len(projects) > 0 {
+		log.Printf("Recorded baseline revisions for %d projects", len(projects))
+	}
+}
+
+// baselineRevision is the revision recorded for a project's current version
+// when it was not saved through the API
+func baselineRevision(project *models.Project) *models.ProjectRevision {
+	return &models.ProjectRevision{
+		ProjectID:       project.ID,
+		Number:          project.Version,
+		AuthorID:        project.OwnerID,
+		Action:          "baseline",
+		Title:           project.Title,
+		FrameTemplateID: project.FrameTemplateID,
+		FrameSize:       project.FrameSize,
+		ProjectData:     project.ProjectData,
+		Objects:         models.SnapshotObjects(project.Objects),
+	}
+}
+
 // SeedDatabase seeds the database with initial data
 func SeedDatabase() {
 	log.Println("Seeding database with initial data...")
 
+	templates := seedFrameTemplates()
+	assignFrameTemplates(templates)
+	seedPriceRates()
+
+	admin := seedAdminUser()
+	if admin == nil {
+		log.Println("No creator account available, skipping seed")
+		return
+	}
+	ensureAdminRole(admin)
+
+	assignUnownedProjects(admin.ID)
+
 	// Check if we already have data
 	var projectCount int64
 	DB.Model(&models.Project{}).Count(&projectCount)
@@ -95,39 +163,61 @@ func SeedDatabase() {
 	// Create sample projects for development
 	sampleProjects := []models.Project{
 		{
-			Title:     "Sample Scrapbook 20x20",
-			FrameSize: "20x20",
+			OwnerID:         admin.ID,
+			Title:           "Sample Scrapbook 20x20",
+			FrameTemplateID: templateIDForSize(templates, "20x20"),
+			FrameSize:       "20x20",
 			ProjectData: []byte(`{
-				"version": "1.0",
+				"version": "1.1",
 				"settings": {
 					"backgroundColor": "#ffffff",
 					"lighting": {
 						"enabled": true,
-						"intensity": 1.0
+						"intensity": 1.0,
+						"positions": []
 					}
 				},
-				"objects": []
+				"objects": [],
+				"camera": {
+					"position": {"x": 0, "y": 0, "z": 60},
+					"target": {"x": 0, "y": 0, "z": 0},
+					"fov": 45
+				}
 			}`),
 		},
 		{
-			Title:     "Demo Project 20x30",
-			FrameSize: "20x30",
+			OwnerID:         admin.ID,
+			Title:           "Demo Project 20x30",
+			FrameTemplateID: templateIDForSize(templates, "20x30"),
+			FrameSize:       "20x30",
 			ProjectData: []byte(`{
-				"version": "1.0",
+				"version": "1.1",
 				"settings": {
 					"backgroundColor": "#f5f5f5",
 					"lighting": {
 						"enabled": true,
-						"intensity": 0.8
+						"intensity": 0.8,
+						"positions": []
 					}
 				},
-				"objects": []
+				"objects": [],
+				"camera": {
+					"position": {"x": 0, "y": 0, "z": 60},
+					"target": {"x": 0, "y": 0, "z": 0},
+					"fov": 45
+				}
 			}`),
 		},
 	}
 
 	for _, project := range sampleProjects {
-		if err := DB.Create(&project).Error; err != nil {
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&project).Error; err != nil {
+				return err
+			}
+			return tx.Create(baselineRevision(&project)).Error
+		})
+		if err != nil {
 			log.Printf("Failed to create sample project: %v", err)
 		}
 	}
@@ -135,6 +225,158 @@ func SeedDatabase() {
 	log.Println("Database seeding completed")
 }
 
+// defaultFrameTemplates are the frames the shop sells out of the box. LED
+// mounts sit just behind the top edge of the front, pointing down into the frame.
+var defaultFrameTemplates = []models.FrameTemplate{
+	{
+		Name: "Square 15x15", WidthCM: 15, HeightCM: 15, DepthCM: 4, MaxLayers: 4, WallMaterial: "MDF",
+		LEDMounts: []models.Vector3{{X: 0, Y: 7, Z: 3.5}},
+	},
+	{
+		Name: "Square 20x20", WidthCM: 20, HeightCM: 20, DepthCM: 5, MaxLayers: 6, WallMaterial: "MDF",
+		LEDMounts: []models.Vector3{{X: -5, Y: 9.5, Z: 4.5}, {X: 5, Y: 9.5, Z: 4.5}},
+	},
+	{
+		Name: "Portrait 20x30", WidthCM: 20, HeightCM: 30, DepthCM: 5, MaxLayers: 6, WallMaterial: "MDF",
+		LEDMounts: []models.Vector3{{X: -5, Y: 14.5, Z: 4.5}, {X: 5, Y: 14.5, Z: 4.5}},
+	},
+	{
+		Name: "Square 30x30", WidthCM: 30, HeightCM: 30, DepthCM: 6, MaxLayers: 8, WallMaterial: "MDF",
+		LEDMounts: []models.Vector3{{X: -10, Y: 14.5, Z: 5.5}, {X: 0, Y: 14.5, Z: 5.5}, {X: 10, Y: 14.5, Z: 5.5}},
+	},
+}
+
+// seedFrameTemplates creates the default frame templates when none exist and
+// returns every template
+func seedFrameTemplates() []models.FrameTemplate {
+	var count int64
+	DB.Model(&models.FrameTemplate{}).Count(&count)
+	if count == 0 {
+		for _, template := range defaultFrameTemplates {
+			if err := DB.Create(&template).Error; err != nil {
+				log.Printf("Failed to create frame template %s: %v", template.Name, err)
+			}
+		}
+	}
+
+	var templates []models.FrameTemplate
+	if err := DB.Order("id ASC").Find(&templates).Error; err != nil {
+		log.Printf("Failed to load frame templates: %v", err)
+	}
+	return templates
+}
+
+// assignFrameTemplates links projects created before frame templates existed
+// to the first template matching their frame size
+func assignFrameTemplates(templates []models.FrameTemplate) {
+	for i := range templates {
+		result := DB.Model(&models.Project{}).
+			Where("frame_template_id IS NULL AND frame_size = ?", templates[i].SizeLabel()).
+			Update("frame_template_id", templates[i].ID)
+		if result.Error != nil {
+			log.Printf("Failed to assign frame templates: %v", result.Error)
+			return
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Assigned frame template %q to %d projects", templates[i].Name, result.RowsAffected)
+		}
+	}
+}
+
+// templateIDForSize returns the ID of the first template with the given frame size
+func templateIDForSize(templates []models.FrameTemplate, size string) *uint {
+	for i := range templates {
+		if templates[i].SizeLabel() == size {
+			return &templates[i].ID
+		}
+	}
+	return nil
+}
+
+// seedPriceRates adds every quote price missing from the price table at its
+// default, leaving prices an admin has changed untouched
+func seedPriceRates() {
+	for _, rate := range pricing.DefaultRates {
+		row := models.PriceRate{Key: rate.Key, Name: rate.Name, Unit: rate.Unit, UnitPrice: rate.UnitPrice}
+		if err := DB.Where(models.PriceRate{Key: rate.Key}).FirstOrCreate(&row).Error; err != nil {
+			log.Printf("Failed to seed price rate %s: %v", rate.Key, err)
+		}
+	}
+}
+
+// seedAdminUser creates the initial creator account when no users exist and
+// returns the first creator account
+func seedAdminUser() *models.User {
+	var existing models.User
+	if err := DB.Order("id ASC").First(&existing).Error; err == nil {
+		return &existing
+	}
+
+	email := os.Getenv("ADMIN_EMAIL")
+	if email == "" {
+		email = "admin@scrapyuk.com"
+	}
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		if os.Getenv("GIN_MODE") == "release" {
+			log.Fatal("ADMIN_PASSWORD must be set in release mode")
+		}
+		password = "scrapyuk2024"
+		log.Println("Warning: ADMIN_PASSWORD not set, seeding admin user with default password")
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		log.Printf("Failed to hash admin password: %v", err)
+		return nil
+	}
+
+	admin := models.User{
+		Email:        strings.ToLower(email),
+		Name:         "ScrapYuk Admin",
+		PasswordHash: hash,
+		Role:         models.RoleAdmin,
+	}
+	if err := DB.Create(&admin).Error; err != nil {
+		log.Printf("Failed to create admin user: %v", err)
+		return nil
+	}
+
+	log.Printf("Created admin user: %s", email)
+	return &admin
+}
+
+// ensureAdminRole promotes the first creator account when no admin exists, as
+// happens for accounts created before roles were introduced
+func ensureAdminRole(first *models.User) {
+	var admins int64
+	DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins)
+	if admins > 0 {
+		return
+	}
+
+	if err := DB.Model(first).Update("role", models.RoleAdmin).Error; err != nil {
+		log.Printf("Failed to promote admin user: %v", err)
+		return
+	}
+	log.Printf("Promoted %s to admin", first.Email)
+}
+
+// assignUnownedProjects hands projects created before ownership existed to the given creator
+func assignUnownedProjects(ownerID uint) {
+	result := DB.Model(&models.Project{}).
+		Where("owner_id IS NULL OR owner_id = 0").
+		Update("owner_id", ownerID)
+	if result.Error != nil {
+		log.Printf("Failed to assign unowned projects: %v", result.Error)
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Assigned %d unowned projects to creator %d", result.RowsAffected, ownerID)
+	}
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
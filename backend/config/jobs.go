@@ -0,0 +1,59 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// JobsConfig holds the settings of the background maintenance jobs
+type JobsConfig struct {
+	Enabled               bool
+	Jitter                time.Duration
+	LinkCleanupInterval   time.Duration
+	OrphanSweepInterval   time.Duration
+	OrphanGracePeriod     time.Duration
+	RevisionPruneInterval time.Duration
+	RevisionRetention     int
+}
+
+// LoadJobsConfig reads the background job settings from the environment
+func LoadJobsConfig() JobsConfig {
+	cfg := JobsConfig{
+		Enabled:               os.Getenv("JOBS_ENABLED") != "false",
+		Jitter:                durationFromEnv("JOBS_JITTER", time.Minute),
+		LinkCleanupInterval:   durationFromEnv("JOB_LINK_CLEANUP_INTERVAL", time.Hour),
+		OrphanSweepInterval:   durationFromEnv("JOB_ORPHAN_SWEEP_INTERVAL", 24*time.Hour),
+		OrphanGracePeriod:     durationFromEnv("JOB_ORPHAN_GRACE_PERIOD", time.Hour),
+		RevisionPruneInterval: durationFromEnv("JOB_REVISION_PRUNE_INTERVAL", 24*time.Hour),
+		RevisionRetention:     100,
+	}
+
+	if value := os.Getenv("REVISION_RETENTION"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			log.Printf("Invalid REVISION_RETENTION %q, using default of %d", value, cfg.RevisionRetention)
+		} else {
+			cfg.RevisionRetention = parsed
+		}
+	}
+
+	return cfg
+}
+
+// durationFromEnv parses a positive duration from the named variable, falling
+// back to def when it is unset or invalid
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Invalid %s %q, using default of %s", name, value, def)
+		return def
+	}
+	return parsed
+}
@@ -112,8 +112,10 @@ func HealthCheckMinIO() error {
 	return nil
 }
 
-// SetupMinIOPolicy sets up a public read policy for the assets bucket
-func SetupMinIOPolicy() {
+// MakeBucketPrivate removes any bucket policy so objects are only readable
+// through the API or presigned URLs. Buckets created by older versions had a
+// public-read policy that exposed every uploaded asset.
+func MakeBucketPrivate() {
 	if MinIOClient == nil {
 		log.Println("MinIO client not available, skipping policy setup")
 		return
@@ -121,24 +123,20 @@ func SetupMinIOPolicy() {
 
 	ctx := context.Background()
 
-	// Define a policy that allows public read access to objects in the bucket
-	policy := fmt.Sprintf(`{
-		"Version": "2012-10-17",
-		"Statement": [
-			{
-				"Effect": "Allow",
-				"Principal": "*",
-				"Action": ["s3:GetObject"],
-				"Resource": ["arn:aws:s3:::%s/*"]
-			}
-		]
-	}`, MinioBucketName)
-
-	err := MinIOClient.SetBucketPolicy(ctx, MinioBucketName, policy)
+	policy, err := MinIOClient.GetBucketPolicy(ctx, MinioBucketName)
 	if err != nil {
-		log.Printf("Warning: Failed to set bucket policy: %v", err)
-		log.Println("Assets may not be publicly accessible")
+		log.Printf("Warning: Failed to read bucket policy: %v", err)
+		return
+	}
+	if policy == "" {
+		return
+	}
+
+	// An empty policy deletes the existing one
+	if err := MinIOClient.SetBucketPolicy(ctx, MinioBucketName, ""); err != nil {
+		log.Printf("Warning: Failed to remove bucket policy: %v", err)
+		log.Println("Assets may still be publicly readable")
 	} else {
-		log.Println("Bucket policy set successfully - assets are publicly readable")
+		log.Println("Removed bucket policy - assets are private")
 	}
 }
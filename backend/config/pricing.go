@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+var QuoteCurrency string
+
+// InitPricing loads the currency project quotes are priced in
+func InitPricing() {
+	QuoteCurrency = strings.ToUpper(strings.TrimSpace(os.Getenv("QUOTE_CURRENCY")))
+	if QuoteCurrency == "" {
+		QuoteCurrency = "USD"
+	}
+}
+
+// GetQuoteCurrency returns the currency of the price table
+func GetQuoteCurrency() string {
+	return QuoteCurrency
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// GetTrustedProxies returns the reverse proxies whose X-Forwarded-For and
+// X-Real-IP headers are believed when resolving a client's IP address, from
+// the comma-separated TRUSTED_PROXIES. It is nil when unset, so the client IP
+// is always the address of the connection.
+func GetTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
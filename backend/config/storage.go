@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"scrapyuk-backend/internal/storage"
+)
+
+var Storage storage.Storage
+
+// InitStorage initializes the asset storage backend selected by STORAGE_DRIVER
+// ("minio" by default, or "local" for a filesystem directory)
+func InitStorage() {
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "minio"
+	}
+
+	switch driver {
+	case "minio":
+		InitMinIO()
+		if !IsMinIOAvailable() {
+			return
+		}
+		MakeBucketPrivate()
+		Storage = storage.NewMinIOStorage(GetMinIOClient(), GetBucketName())
+
+	case "local":
+		dir := os.Getenv("STORAGE_LOCAL_PATH")
+		if dir == "" {
+			dir = "./data/assets"
+		}
+
+		local, err := storage.NewLocalStorage(dir)
+		if err != nil {
+			log.Printf("Failed to initialize local storage: %v", err)
+			log.Println("Storage will be unavailable - file uploads will not work")
+			return
+		}
+		Storage = local
+		log.Printf("Local storage initialized successfully (path: %s)", dir)
+
+	default:
+		log.Printf("Unknown STORAGE_DRIVER %q, storage will be unavailable", driver)
+	}
+}
+
+// GetStorage returns the configured storage backend
+func GetStorage() storage.Storage {
+	return Storage
+}
+
+// IsStorageAvailable checks if a storage backend is configured and reachable
+func IsStorageAvailable() bool {
+	return Storage != nil
+}
+
+// HealthCheckStorage performs a health check on the storage backend
+func HealthCheckStorage() error {
+	if Storage == nil {
+		return fmt.Errorf("storage backend not initialized")
+	}
+	return Storage.HealthCheck(context.Background())
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidToken is returned when a token is malformed, expired or has a bad signature
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims represents the JWT claims issued to a logged-in creator
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// UserID returns the creator ID stored in the token subject
+func (c *Claims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, 32)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return uint(id), nil
+}
+
+// IssueToken signs a new session token for the given user. The returned token ID
+// is stored server-side so the session can be revoked before it expires.
+func IssueToken(secret []byte, userID uint, ttl time.Duration) (token string, tokenID string, expiresAt time.Time, err error) {
+	now := time.Now()
+	expiresAt = now.Add(ttl)
+	tokenID = uuid.New().String()
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Issuer:    "scrapyuk-backend",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return token, tokenID, expiresAt, nil
+}
+
+// ParseToken verifies the token signature and expiry and returns its claims
+func ParseToken(secret []byte, token string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer("scrapyuk-backend"))
+	if err != nil || !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	// Viewer tokens carry an audience and must never pass as creator sessions
+	if len(claims.Audience) > 0 {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// viewerAudience marks tokens that grant read access to a single shared link
+const viewerAudience = "shared-viewer"
+
+// IssueViewerToken signs a short-lived token granting access to the shared link
+// with the given token after it has been unlocked
+func IssueViewerToken(secret []byte, linkToken string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims := jwt.RegisteredClaims{
+		Subject:   linkToken,
+		Audience:  jwt.ClaimStrings{viewerAudience},
+		Issuer:    "scrapyuk-backend",
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// VerifyViewerToken checks that token is a valid viewer token for the shared link
+func VerifyViewerToken(secret []byte, token, linkToken string) error {
+	claims := &jwt.RegisteredClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer("scrapyuk-backend"),
+		jwt.WithAudience(viewerAudience),
+		jwt.WithSubject(linkToken))
+	if err != nil || !parsed.Valid {
+		return ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package cutline
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// cutLineWidth is the stroke width of the outlines in the SVG, in cm
+const cutLineWidth = 0.02
+
+// SVG draws the outlines at their printed size. The view box covers both the
+// image and the outlines, so the file lines up with the artwork when overlaid.
+func (c *Cutline) SVG() []byte {
+	minX, minY := math.Min(0, c.Min.X), math.Min(0, c.Min.Y)
+	maxX, maxY := math.Max(c.WidthCM, c.Max.X), math.Max(c.HeightCM, c.Max.Y)
+	w, h := maxX-minX, maxY-minY
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%scm" height="%scm" viewBox="%s %s %s %s">`+"\n",
+		num(w), num(h), num(minX), num(minY), num(w), num(h))
+	for i, outline := range c.Outlines {
+		fmt.Fprintf(&buf, `<path id="cut-%d" d="`, i+1)
+		for j, p := range outline {
+			command := "L"
+			if j == 0 {
+				command = "M"
+			}
+			fmt.Fprintf(&buf, "%s%s %s ", command, num(p.X), num(p.Y))
+		}
+		fmt.Fprintf(&buf, `Z" fill="none" stroke="#ff0000" stroke-width="%s"/>`+"\n", num(cutLineWidth))
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// DXF writes the outlines as closed polylines on a CUT layer of an AutoCAD R12
+// drawing, the version cutting plotter software reads most widely. Units are
+// millimetres with y up, measured from the bottom-left corner of the image.
+func (c *Cutline) DXF() []byte {
+	var buf bytes.Buffer
+	group := func(code int, value string) {
+		fmt.Fprintf(&buf, "%3d\n%s\n", code, value)
+	}
+	mm := func(cm float64) string { return num(cm * 10) }
+
+	group(0, "SECTION")
+	group(2, "HEADER")
+	group(9, "$ACADVER")
+	group(1, "AC1009")
+	group(9, "$INSUNITS")
+	group(70, "4")
+	group(0, "ENDSEC")
+
+	group(0, "SECTION")
+	group(2, "ENTITIES")
+	for _, outline := range c.Outlines {
+		group(0, "POLYLINE")
+		group(8, "CUT")
+		group(66, "1")
+		group(10, "0")
+		group(20, "0")
+		group(30, "0")
+		group(70, "1")
+		for _, p := range outline {
+			group(0, "VERTEX")
+			group(8, "CUT")
+			group(10, mm(p.X))
+			group(20, mm(c.HeightCM-p.Y))
+			group(30, "0")
+		}
+		group(0, "SEQEND")
+		group(8, "CUT")
+	}
+	group(0, "ENDSEC")
+	group(0, "EOF")
+	return buf.Bytes()
+}
+
+// num formats a length to four decimals without trailing zeros
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10000)/10000, 'f', -1, 64)
+}
@@ -0,0 +1,457 @@
+// Package cutline traces the outer silhouette of an image's opaque pixels into
+// closed outlines a cutting plotter can follow.
+//
+// Outline coordinates are in centimetres from the top-left corner of the image
+// at its printed size, with y running down as in the image.
+package cutline
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// MaxTraceDimension is the longest edge, in pixels, of the mask outlines are
+// traced from. Larger images are averaged down first, which bounds the memory
+// and time tracing takes; it is still finer than a plotter blade can follow.
+const MaxTraceDimension = 2048
+
+// MaxOffsetPixels is the largest offset, in pixels of the traced mask. The
+// mask is padded by the offset and a distance is stored for every pixel, so
+// the offset bounds the memory tracing takes just as the image size does.
+const MaxOffsetPixels = 256
+
+// ErrOffsetTooLarge is returned when the offset exceeds MaxOffsetPixels
+var ErrOffsetTooLarge = errors.New("offset too large")
+
+// Options control how the silhouette is traced
+type Options struct {
+	// CMPerPixel is the printed size of one pixel of the image
+	CMPerPixel float64
+	// Threshold is the lowest alpha, 1-255, counted as part of the artwork
+	Threshold uint8
+	// OffsetCM moves the outline outwards, or inwards when negative
+	OffsetCM float64
+	// ToleranceCM is how far the simplified outline may stray from the traced one
+	ToleranceCM float64
+}
+
+// Point is a position in cm
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Cutline is the traced silhouette of an image
+type Cutline struct {
+	// WidthCM and HeightCM are the printed size of the image
+	WidthCM  float64 `json:"width_cm"`
+	HeightCM float64 `json:"height_cm"`
+	// Min and Max bound the outlines, which an outward offset can push past
+	// the edges of the image
+	Min Point `json:"min"`
+	Max Point `json:"max"`
+	// Outlines are closed, clockwise as drawn, largest first
+	Outlines [][]Point `json:"outlines"`
+}
+
+// Check returns an error wrapping ErrOffsetTooLarge if the offset is too
+// large to trace an image of the given size with
+func (o Options) Check(width, height int) error {
+	cmPerPixel := o.CMPerPixel * float64(blockSize(width, height))
+	if math.Abs(o.OffsetCM)/cmPerPixel > MaxOffsetPixels {
+		return fmt.Errorf("%w: at this scale the offset can be at most %.2f cm", ErrOffsetTooLarge, MaxOffsetPixels*cmPerPixel)
+	}
+	return nil
+}
+
+// Trace finds the outer outline of every separate part of img's artwork.
+// Holes inside a part are not cut.
+func Trace(img image.Image, opts Options) (*Cutline, error) {
+	bounds := img.Bounds()
+	if err := opts.Check(bounds.Dx(), bounds.Dy()); err != nil {
+		return nil, err
+	}
+	mask, block := alphaMask(img, opts.Threshold)
+	cmPerPixel := opts.CMPerPixel * float64(block)
+
+	// Pad the mask so an outward offset has room to grow and every outline
+	// is closed inside it
+	radius := opts.OffsetCM / cmPerPixel
+	pad := 1
+	if radius > 0 {
+		pad += int(math.Ceil(radius))
+	}
+	mask = mask.padded(pad)
+	if radius != 0 {
+		mask = mask.offset(radius)
+	}
+	mask.fillHoles()
+
+	tolerance := opts.ToleranceCM / cmPerPixel
+	minArea := math.Max(tolerance*tolerance, 1)
+
+	type loop struct {
+		points []Point
+		area   float64
+	}
+	var loops []loop
+	for _, traced := range mask.trace() {
+		simplified := simplify(traced, tolerance)
+		area := polygonArea(simplified)
+		if len(simplified) < 3 || math.Abs(area) < minArea {
+			continue
+		}
+		loops = append(loops, loop{points: simplified, area: math.Abs(area)})
+	}
+	sort.SliceStable(loops, func(a, b int) bool { return loops[a].area > loops[b].area })
+
+	cut := &Cutline{
+		WidthCM:  round(float64(bounds.Dx()) * opts.CMPerPixel),
+		HeightCM: round(float64(bounds.Dy()) * opts.CMPerPixel),
+		Outlines: [][]Point{},
+	}
+	first := true
+	for _, l := range loops {
+		outline := make([]Point, len(l.points))
+		for i, p := range l.points {
+			outline[i] = Point{X: round((p.X - float64(pad)) * cmPerPixel), Y: round((p.Y - float64(pad)) * cmPerPixel)}
+			if first {
+				cut.Min, cut.Max = outline[i], outline[i]
+				first = false
+			}
+			cut.Min.X, cut.Min.Y = math.Min(cut.Min.X, outline[i].X), math.Min(cut.Min.Y, outline[i].Y)
+			cut.Max.X, cut.Max.Y = math.Max(cut.Max.X, outline[i].X), math.Max(cut.Max.Y, outline[i].Y)
+		}
+		cut.Outlines = append(cut.Outlines, outline)
+	}
+	return cut, nil
+}
+
+// mask is a grid of pixels inside (true) or outside the artwork
+type mask struct {
+	w, h   int
+	pixels []bool
+}
+
+func (m *mask) at(x, y int) bool {
+	return x >= 0 && y >= 0 && x < m.w && y < m.h && m.pixels[y*m.w+x]
+}
+
+// alphaMask thresholds the alpha channel of img. Images larger than
+// MaxTraceDimension are averaged down in square blocks of pixels; the block
+// size is returned with the mask.
+func alphaMask(img image.Image, threshold uint8) (*mask, int) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	block := blockSize(width, height)
+
+	m := &mask{w: (width + block - 1) / block, h: (height + block - 1) / block}
+	m.pixels = make([]bool, m.w*m.h)
+	sums := make([]uint32, m.w*m.h)
+	counts := make([]uint32, m.w*m.h)
+	alpha := func(x, y int) uint32 {
+		_, _, _, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+		return a >> 8
+	}
+	// Uploads decode to NRGBA, so read their alpha directly
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		alpha = func(x, y int) uint32 {
+			return uint32(nrgba.Pix[nrgba.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)+3])
+		}
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := (y/block)*m.w + x/block
+			sums[i] += alpha(x, y)
+			counts[i]++
+		}
+	}
+	for i := range m.pixels {
+		m.pixels[i] = sums[i] >= uint32(threshold)*counts[i]
+	}
+	return m, block
+}
+
+// blockSize returns how many pixels of an image of the given size are averaged
+// into each side of a mask pixel
+func blockSize(width, height int) int {
+	return max(1, (max(width, height)+MaxTraceDimension-1)/MaxTraceDimension)
+}
+
+// padded returns the mask with n empty pixels added on every side
+func (m *mask) padded(n int) *mask {
+	p := &mask{w: m.w + 2*n, h: m.h + 2*n}
+	p.pixels = make([]bool, p.w*p.h)
+	for y := 0; y < m.h; y++ {
+		copy(p.pixels[(y+n)*p.w+n:], m.pixels[y*m.w:(y+1)*m.w])
+	}
+	return p
+}
+
+// offset grows the mask by radius pixels, or shrinks it when radius is
+// negative, using the exact Euclidean distance between pixel centres
+func (m *mask) offset(radius float64) *mask {
+	grow := radius > 0
+	// Distances are measured to the nearest pixel on the other side of the edge
+	dist := distanceTransform(m, grow)
+	r2 := radius * radius
+
+	out := &mask{w: m.w, h: m.h, pixels: make([]bool, len(m.pixels))}
+	for i, d := range dist {
+		if grow {
+			out.pixels[i] = d <= r2
+		} else {
+			out.pixels[i] = m.pixels[i] && d > r2
+		}
+	}
+	return out
+}
+
+// distanceTransform returns the squared distance from every pixel to the
+// nearest pixel whose mask value is target
+func distanceTransform(m *mask, target bool) []float64 {
+	inf := float64(m.w*m.w + m.h*m.h)
+	dist := make([]float64, len(m.pixels))
+	for i, inside := range m.pixels {
+		if inside == target {
+			dist[i] = 0
+		} else {
+			dist[i] = inf
+		}
+	}
+
+	n := max(m.w, m.h)
+	f, d := make([]float64, n), make([]float64, n)
+	v, z := make([]int, n), make([]float64, n+1)
+	for x := 0; x < m.w; x++ {
+		for y := 0; y < m.h; y++ {
+			f[y] = dist[y*m.w+x]
+		}
+		distance1D(f[:m.h], d[:m.h], v, z)
+		for y := 0; y < m.h; y++ {
+			dist[y*m.w+x] = d[y]
+		}
+	}
+	for y := 0; y < m.h; y++ {
+		copy(f, dist[y*m.w:(y+1)*m.w])
+		distance1D(f[:m.w], d[:m.w], v, z)
+		copy(dist[y*m.w:], d[:m.w])
+	}
+	return dist
+}
+
+// distance1D is the one-dimensional squared distance transform of Felzenszwalb
+// and Huttenlocher: the lower envelope of the parabolas rooted at f
+func distance1D(f, d []float64, v []int, z []float64) {
+	k := 0
+	v[0] = 0
+	z[0], z[1] = math.Inf(-1), math.Inf(1)
+	for q := 1; q < len(f); q++ {
+		s := intersection(f, q, v[k])
+		for s <= z[k] {
+			k--
+			s = intersection(f, q, v[k])
+		}
+		k++
+		v[k] = q
+		z[k], z[k+1] = s, math.Inf(1)
+	}
+
+	k = 0
+	for q := range f {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		p := v[k]
+		d[q] = float64((q-p)*(q-p)) + f[p]
+	}
+}
+
+// intersection returns where the parabolas rooted at q and p cross
+func intersection(f []float64, q, p int) float64 {
+	return ((f[q] + float64(q*q)) - (f[p] + float64(p*p))) / float64(2*(q-p))
+}
+
+// fillHoles marks every pixel not reachable from the edge of the mask as
+// inside, so only outer outlines are traced
+func (m *mask) fillHoles() {
+	reached := make([]bool, len(m.pixels))
+	var stack []int
+	push := func(x, y int) {
+		if x < 0 || y < 0 || x >= m.w || y >= m.h {
+			return
+		}
+		i := y*m.w + x
+		if reached[i] || m.pixels[i] {
+			return
+		}
+		reached[i] = true
+		stack = append(stack, i)
+	}
+	for x := 0; x < m.w; x++ {
+		push(x, 0)
+		push(x, m.h-1)
+	}
+	for y := 0; y < m.h; y++ {
+		push(0, y)
+		push(m.w-1, y)
+	}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := i%m.w, i/m.w
+		push(x+1, y)
+		push(x-1, y)
+		push(x, y+1)
+		push(x, y-1)
+	}
+	for i := range m.pixels {
+		m.pixels[i] = !reached[i]
+	}
+}
+
+// Directions along pixel edges, clockwise as drawn with y down
+const (
+	east = iota
+	south
+	west
+	north
+)
+
+var steps = [4][2]int{east: {1, 0}, south: {0, 1}, west: {-1, 0}, north: {0, -1}}
+
+// trace follows the pixel edges between inside and outside, keeping the inside
+// on the right, and returns every closed loop by its corners. Diagonally
+// touching pixels are joined into one loop.
+func (m *mask) trace() [][]Point {
+	// Edges start at pixel corners; a corner has at most two outgoing edges
+	cols := m.w + 1
+	edges := make([]uint8, cols*(m.h+1))
+	for y := 0; y < m.h; y++ {
+		for x := 0; x < m.w; x++ {
+			if !m.pixels[y*m.w+x] {
+				continue
+			}
+			if !m.at(x, y-1) {
+				edges[y*cols+x] |= 1 << east
+			}
+			if !m.at(x+1, y) {
+				edges[y*cols+x+1] |= 1 << south
+			}
+			if !m.at(x, y+1) {
+				edges[(y+1)*cols+x+1] |= 1 << west
+			}
+			if !m.at(x-1, y) {
+				edges[(y+1)*cols+x] |= 1 << north
+			}
+		}
+	}
+
+	var loops [][]Point
+	for start := range edges {
+		for edges[start] != 0 {
+			x, y := start%cols, start/cols
+			dir := east
+			for edges[start]&(1<<dir) == 0 {
+				dir++
+			}
+
+			var points []Point
+			prev := -1
+			for {
+				i := y*cols + x
+				if edges[i]&(1<<dir) == 0 {
+					break
+				}
+				edges[i] &^= 1 << dir
+				if dir != prev {
+					points = append(points, Point{X: float64(x), Y: float64(y)})
+				}
+				prev = dir
+				x, y = x+steps[dir][0], y+steps[dir][1]
+
+				// Prefer turning left, then straight on, then right
+				next := edges[y*cols+x]
+				for _, turn := range []int{3, 0, 1} {
+					if d := (dir + turn) % 4; next&(1<<d) != 0 {
+						dir = d
+						break
+					}
+				}
+			}
+			if len(points) > 1 && points[0] == points[len(points)-1] {
+				points = points[:len(points)-1]
+			}
+			loops = append(loops, points)
+		}
+	}
+	return loops
+}
+
+// simplify reduces a closed loop with the Ramer-Douglas-Peucker method,
+// splitting it at its first point and the point farthest from it
+func simplify(loop []Point, tolerance float64) []Point {
+	if len(loop) < 4 || tolerance <= 0 {
+		return loop
+	}
+	far, farDist := 0, -1.0
+	for i, p := range loop {
+		if d := math.Hypot(p.X-loop[0].X, p.Y-loop[0].Y); d > farDist {
+			far, farDist = i, d
+		}
+	}
+
+	closed := append(append([]Point{}, loop...), loop[0])
+	first := douglasPeucker(closed[:far+1], tolerance)
+	second := douglasPeucker(closed[far:], tolerance)
+	return append(first[:len(first)-1], second[:len(second)-1]...)
+}
+
+// douglasPeucker simplifies an open polyline, keeping both ends
+func douglasPeucker(line []Point, tolerance float64) []Point {
+	if len(line) < 3 {
+		return line
+	}
+	a, b := line[0], line[len(line)-1]
+	index, maxDist := 0, 0.0
+	for i := 1; i < len(line)-1; i++ {
+		if d := segmentDistance(line[i], a, b); d > maxDist {
+			index, maxDist = i, d
+		}
+	}
+	if maxDist <= tolerance {
+		return []Point{a, b}
+	}
+	left := douglasPeucker(line[:index+1], tolerance)
+	right := douglasPeucker(line[index:], tolerance)
+	return append(left[:len(left)-1], right...)
+}
+
+// segmentDistance returns the distance from p to the segment ab
+func segmentDistance(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length := dx*dx + dy*dy
+	if length == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t := math.Max(0, math.Min(1, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/length))
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
+
+// polygonArea returns the signed area of a closed polygon
+func polygonArea(points []Point) float64 {
+	var area float64
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	return area / 2
+}
+
+// round rounds a length in cm to a thousandth of a millimetre
+func round(cm float64) float64 {
+	return math.Round(cm*10000) / 10000
+}
@@ -0,0 +1,116 @@
+package cutline
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// cmPerPixel prints one pixel as a millimetre, to keep the expected values readable
+const cmPerPixel = 0.1
+
+// filled returns a w x h image that is opaque inside rect and transparent elsewhere
+func filled(w, h int, rect image.Rectangle) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	return img
+}
+
+func trace(t *testing.T, img image.Image, offsetCM float64) *Cutline {
+	t.Helper()
+	cut, err := Trace(img, Options{CMPerPixel: cmPerPixel, Threshold: 128, OffsetCM: offsetCM, ToleranceCM: 0.01})
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+	return cut
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestTraceTransparent(t *testing.T) {
+	cut := trace(t, filled(40, 30, image.Rectangle{}), 0)
+	if len(cut.Outlines) != 0 {
+		t.Fatalf("got %d outlines, want none", len(cut.Outlines))
+	}
+	if !near(cut.WidthCM, 4) || !near(cut.HeightCM, 3) {
+		t.Errorf("size = %gx%g cm, want 4x3", cut.WidthCM, cut.HeightCM)
+	}
+}
+
+func TestTraceOpaque(t *testing.T) {
+	cut := trace(t, filled(40, 30, image.Rect(0, 0, 40, 30)), 0)
+	if len(cut.Outlines) != 1 {
+		t.Fatalf("got %d outlines, want 1", len(cut.Outlines))
+	}
+	want := []Point{{0, 0}, {4, 0}, {4, 3}, {0, 3}}
+	got := cut.Outlines[0]
+	if len(got) != len(want) {
+		t.Fatalf("outline = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !near(got[i].X, want[i].X) || !near(got[i].Y, want[i].Y) {
+			t.Fatalf("outline = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestTraceIgnoresHoles(t *testing.T) {
+	img := filled(40, 40, image.Rect(5, 5, 35, 35))
+	for y := 15; y < 25; y++ {
+		for x := 15; x < 25; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{})
+		}
+	}
+	cut := trace(t, img, 0)
+	if len(cut.Outlines) != 1 {
+		t.Fatalf("got %d outlines, want only the outer one", len(cut.Outlines))
+	}
+}
+
+func TestTraceOffset(t *testing.T) {
+	img := filled(60, 60, image.Rect(20, 20, 40, 40))
+
+	cases := []struct {
+		name     string
+		offsetCM float64
+		min, max float64
+	}{
+		{"outward", 0.5, 1.5, 4.5},
+		{"inward", -0.5, 2.5, 3.5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cut := trace(t, img, tc.offsetCM)
+			if len(cut.Outlines) != 1 {
+				t.Fatalf("got %d outlines, want 1", len(cut.Outlines))
+			}
+			// Offsets are measured between pixel centres, so edges land within a pixel
+			for _, v := range []float64{cut.Min.X, cut.Min.Y} {
+				if math.Abs(v-tc.min) > cmPerPixel {
+					t.Errorf("min = %v, want about %g", cut.Min, tc.min)
+				}
+			}
+			for _, v := range []float64{cut.Max.X, cut.Max.Y} {
+				if math.Abs(v-tc.max) > cmPerPixel {
+					t.Errorf("max = %v, want about %g", cut.Max, tc.max)
+				}
+			}
+		})
+	}
+}
+
+func TestTraceOffsetTooLarge(t *testing.T) {
+	img := filled(10, 10, image.Rect(0, 0, 10, 10))
+	_, err := Trace(img, Options{CMPerPixel: 0.001, Threshold: 128, OffsetCM: 2})
+	if !errors.Is(err, ErrOffsetTooLarge) {
+		t.Fatalf("err = %v, want ErrOffsetTooLarge", err)
+	}
+}
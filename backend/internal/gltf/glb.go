@@ -0,0 +1,307 @@
+// Package gltf builds a binary glTF 2.0 (GLB) model of an assembled scene: the
+// frame, every paper layer as a textured quad and the LED lights.
+//
+// glTF is measured in metres with y up and +z towards the viewer, so scene
+// coordinates only need converting from centimetres.
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+)
+
+// GLB container constants from the glTF 2.0 specification
+const (
+	glbMagic       = 0x46546C67 // "glTF"
+	glbVersion     = 2
+	chunkJSON      = 0x4E4F534A // "JSON"
+	chunkBIN       = 0x004E4942 // "BIN\0"
+	lightsPunctual = "KHR_lights_punctual"
+)
+
+// Accessor component types, buffer view targets and sampler settings, as
+// numbered by the specification
+const (
+	componentFloat       = 5126
+	componentUnsignedInt = 5125
+	targetArrayBuffer    = 34962
+	targetElementBuffer  = 34963
+	filterLinear         = 9729
+	filterLinearMipmap   = 9987
+	wrapClampToEdge      = 33071
+)
+
+type document struct {
+	Asset          assetInfo                  `json:"asset"`
+	ExtensionsUsed []string                   `json:"extensionsUsed,omitempty"`
+	Extensions     map[string]lightsExtension `json:"extensions,omitempty"`
+	Scene          int                        `json:"scene"`
+	Scenes         []sceneNodes               `json:"scenes"`
+	Nodes          []node                     `json:"nodes"`
+	Meshes         []mesh                     `json:"meshes,omitempty"`
+	Materials      []material                 `json:"materials,omitempty"`
+	Textures       []texture                  `json:"textures,omitempty"`
+	Images         []imageRef                 `json:"images,omitempty"`
+	Samplers       []sampler                  `json:"samplers,omitempty"`
+	Accessors      []accessor                 `json:"accessors,omitempty"`
+	BufferViews    []bufferView               `json:"bufferViews,omitempty"`
+	Buffers        []buffer                   `json:"buffers,omitempty"`
+}
+
+type assetInfo struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type sceneNodes struct {
+	Name  string `json:"name,omitempty"`
+	Nodes []int  `json:"nodes"`
+}
+
+type node struct {
+	Name        string          `json:"name,omitempty"`
+	Mesh        *int            `json:"mesh,omitempty"`
+	Translation *[3]float64     `json:"translation,omitempty"`
+	Rotation    *[4]float64     `json:"rotation,omitempty"`
+	Extensions  *nodeExtensions `json:"extensions,omitempty"`
+}
+
+type nodeExtensions struct {
+	Light lightRef `json:"KHR_lights_punctual"`
+}
+
+type lightRef struct {
+	Light int `json:"light"`
+}
+
+type lightsExtension struct {
+	Lights []light `json:"lights"`
+}
+
+type light struct {
+	Name      string     `json:"name,omitempty"`
+	Type      string     `json:"type"`
+	Color     [3]float64 `json:"color"`
+	Intensity float64    `json:"intensity"`
+}
+
+type mesh struct {
+	Name       string      `json:"name,omitempty"`
+	Primitives []primitive `json:"primitives"`
+}
+
+type primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   int            `json:"material"`
+}
+
+type material struct {
+	Name                 string               `json:"name,omitempty"`
+	PBRMetallicRoughness pbrMetallicRoughness `json:"pbrMetallicRoughness"`
+	AlphaMode            string               `json:"alphaMode,omitempty"`
+	AlphaCutoff          *float64             `json:"alphaCutoff,omitempty"`
+	DoubleSided          bool                 `json:"doubleSided,omitempty"`
+}
+
+type pbrMetallicRoughness struct {
+	BaseColorFactor  *[4]float64 `json:"baseColorFactor,omitempty"`
+	BaseColorTexture *textureRef `json:"baseColorTexture,omitempty"`
+	MetallicFactor   float64     `json:"metallicFactor"`
+	RoughnessFactor  float64     `json:"roughnessFactor"`
+}
+
+type textureRef struct {
+	Index int `json:"index"`
+}
+
+type texture struct {
+	Sampler int `json:"sampler"`
+	Source  int `json:"source"`
+}
+
+type imageRef struct {
+	Name       string `json:"name,omitempty"`
+	BufferView int    `json:"bufferView"`
+	MimeType   string `json:"mimeType"`
+}
+
+type sampler struct {
+	MagFilter int `json:"magFilter"`
+	MinFilter int `json:"minFilter"`
+	WrapS     int `json:"wrapS"`
+	WrapT     int `json:"wrapT"`
+}
+
+type accessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float64 `json:"min,omitempty"`
+	Max           []float64 `json:"max,omitempty"`
+}
+
+type bufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target,omitempty"`
+}
+
+type buffer struct {
+	ByteLength int `json:"byteLength"`
+}
+
+// geometry is the vertex data of one mesh primitive, in metres
+type geometry struct {
+	positions [][3]float32
+	normals   [][3]float32
+	uvs       [][2]float32
+	indices   []uint32
+}
+
+// quad adds a rectangle with corner o and edges u and v. It faces along u × v.
+// Texture coordinates map the whole image with its top-left corner at o + v.
+func (g *geometry) quad(o, u, v [3]float32) {
+	base := uint32(len(g.positions))
+	normal := normalize(cross(u, v))
+	for _, corner := range [][3]float32{o, add(o, u), add(add(o, u), v), add(o, v)} {
+		g.positions = append(g.positions, corner)
+		g.normals = append(g.normals, normal)
+	}
+	g.uvs = append(g.uvs, [2]float32{0, 1}, [2]float32{1, 1}, [2]float32{1, 0}, [2]float32{0, 0})
+	g.indices = append(g.indices, base, base+1, base+2, base, base+2, base+3)
+}
+
+// box adds an axis-aligned box between min and max with outward faces
+func (g *geometry) box(min, max [3]float32) {
+	dx := [3]float32{max[0] - min[0], 0, 0}
+	dy := [3]float32{0, max[1] - min[1], 0}
+	dz := [3]float32{0, 0, max[2] - min[2]}
+	g.quad([3]float32{max[0], min[1], min[2]}, dy, dz)
+	g.quad(min, dz, dy)
+	g.quad([3]float32{min[0], max[1], min[2]}, dz, dx)
+	g.quad(min, dx, dz)
+	g.quad([3]float32{min[0], min[1], max[2]}, dx, dy)
+	g.quad(min, dy, dx)
+}
+
+// encoder collects the glTF document and its binary buffer
+type encoder struct {
+	doc document
+	bin bytes.Buffer
+}
+
+// view appends data to the binary buffer, aligned to four bytes, and returns
+// its buffer view
+func (e *encoder) view(data []byte, target int) int {
+	for e.bin.Len()%4 != 0 {
+		e.bin.WriteByte(0)
+	}
+	e.doc.BufferViews = append(e.doc.BufferViews, bufferView{
+		ByteOffset: e.bin.Len(),
+		ByteLength: len(data),
+		Target:     target,
+	})
+	e.bin.Write(data)
+	return len(e.doc.BufferViews) - 1
+}
+
+// accessor appends an accessor over a new buffer view of data
+func (e *encoder) accessor(data []byte, target, componentType, count int, kind string, min, max []float64) int {
+	e.doc.Accessors = append(e.doc.Accessors, accessor{
+		BufferView:    e.view(data, target),
+		ComponentType: componentType,
+		Count:         count,
+		Type:          kind,
+		Min:           min,
+		Max:           max,
+	})
+	return len(e.doc.Accessors) - 1
+}
+
+// mesh stores the geometry as a single-primitive mesh and returns its index
+func (e *encoder) mesh(name string, g *geometry, materialIndex int) int {
+	lo := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+	hi := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range g.positions {
+		for i := range p {
+			lo[i], hi[i] = math.Min(lo[i], float64(p[i])), math.Max(hi[i], float64(p[i]))
+		}
+	}
+
+	attributes := map[string]int{
+		"POSITION": e.accessor(encode(g.positions), targetArrayBuffer, componentFloat, len(g.positions), "VEC3", lo, hi),
+		"NORMAL":   e.accessor(encode(g.normals), targetArrayBuffer, componentFloat, len(g.normals), "VEC3", nil, nil),
+	}
+	if len(g.uvs) > 0 {
+		attributes["TEXCOORD_0"] = e.accessor(encode(g.uvs), targetArrayBuffer, componentFloat, len(g.uvs), "VEC2", nil, nil)
+	}
+	indices := e.accessor(encode(g.indices), targetElementBuffer, componentUnsignedInt, len(g.indices), "SCALAR", nil, nil)
+
+	e.doc.Meshes = append(e.doc.Meshes, mesh{
+		Name:       name,
+		Primitives: []primitive{{Attributes: attributes, Indices: indices, Material: materialIndex}},
+	})
+	return len(e.doc.Meshes) - 1
+}
+
+// glb packs the document and binary buffer into a GLB container
+func (e *encoder) glb() ([]byte, error) {
+	for e.bin.Len()%4 != 0 {
+		e.bin.WriteByte(0)
+	}
+	if e.bin.Len() > 0 {
+		e.doc.Buffers = []buffer{{ByteLength: e.bin.Len()}}
+	}
+
+	content, err := json.Marshal(e.doc)
+	if err != nil {
+		return nil, err
+	}
+	for len(content)%4 != 0 {
+		content = append(content, ' ')
+	}
+
+	length := 12 + 8 + len(content)
+	if e.bin.Len() > 0 {
+		length += 8 + e.bin.Len()
+	}
+
+	var out bytes.Buffer
+	out.Grow(length)
+	binary.Write(&out, binary.LittleEndian, []uint32{glbMagic, glbVersion, uint32(length)})
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(content)), chunkJSON})
+	out.Write(content)
+	if e.bin.Len() > 0 {
+		binary.Write(&out, binary.LittleEndian, []uint32{uint32(e.bin.Len()), chunkBIN})
+		out.Write(e.bin.Bytes())
+	}
+	return out.Bytes(), nil
+}
+
+// encode writes fixed-size values in little-endian order
+func encode(data any) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, data)
+	return buf.Bytes()
+}
+
+func add(a, b [3]float32) [3]float32 {
+	return [3]float32{a[0] + b[0], a[1] + b[1], a[2] + b[2]}
+}
+
+func cross(a, b [3]float32) [3]float32 {
+	return [3]float32{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func normalize(v [3]float32) [3]float32 {
+	length := float32(math.Sqrt(float64(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])))
+	if length == 0 {
+		return v
+	}
+	return [3]float32{v[0] / length, v[1] / length, v[2] / length}
+}
@@ -0,0 +1,241 @@
+package gltf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"scrapyuk-backend/internal/scene"
+)
+
+// Frame construction, in centimetres. The walls surround the interior of the
+// frame and the back panel sits behind it.
+const (
+	wallThicknessCM = 1.5
+	backPanelCM     = 0.5
+	// layerLiftCM keeps a layer lying on the back panel from flickering
+	// through it in viewers
+	layerLiftCM = 0.05
+)
+
+// candelaPerIntensity converts the scene's lighting intensity to the
+// luminous intensity of each glTF point light
+const candelaPerIntensity = 1.0
+
+// wallColors are the sRGB colours of the known wall materials
+var wallColors = map[string]string{
+	"mdf":    "#b9966f",
+	"oak":    "#c8a165",
+	"walnut": "#5d4330",
+	"pine":   "#dcbf8c",
+	"white":  "#f2f2ee",
+	"black":  "#222222",
+}
+
+// defaultWallColor is used for wall materials without a known colour
+const defaultWallColor = "#d8d3cb"
+
+// Frame is the frame the scene is assembled in
+type Frame struct {
+	Name         string
+	WidthCM      float64
+	HeightCM     float64
+	DepthCM      float64
+	WallMaterial string
+	// LEDMounts light the scene when its lighting has no positions of its own
+	LEDMounts []scene.Vector3
+}
+
+// Asset is the image an object is printed from. Width and Height are those of
+// the original, which sets the printed size; PNG may be a smaller rendition.
+type Asset struct {
+	Width  int
+	Height int
+	PNG    []byte
+}
+
+// Build returns the scene as a GLB file. Objects without a known asset are
+// left out.
+func Build(doc *scene.Document, frame Frame, assets map[scene.Ref]Asset) ([]byte, error) {
+	e := &encoder{doc: document{
+		Asset:  assetInfo{Version: "2.0", Generator: "scrapyuk-backend"},
+		Scenes: []sceneNodes{{Name: frame.Name}},
+		Nodes:  []node{},
+	}}
+	root := &e.doc.Scenes[0]
+	addNode := func(n node) {
+		e.doc.Nodes = append(e.doc.Nodes, n)
+		root.Nodes = append(root.Nodes, len(e.doc.Nodes)-1)
+	}
+
+	// Frame: a back panel and four walls around the interior
+	wallColor, known := wallColors[strings.ToLower(strings.TrimSpace(frame.WallMaterial))]
+	if !known {
+		wallColor = defaultWallColor
+	}
+	e.doc.Materials = append(e.doc.Materials, material{
+		Name: "Frame",
+		PBRMetallicRoughness: pbrMetallicRoughness{
+			BaseColorFactor: colorFactor(wallColor),
+			RoughnessFactor: 0.8,
+		},
+	})
+	w, h, d := frame.WidthCM/2, frame.HeightCM/2, frame.DepthCM
+	t := wallThicknessCM
+	var walls geometry
+	walls.box(point(-w-t, -h-t, -backPanelCM), point(w+t, h+t, 0))
+	walls.box(point(-w-t, -h-t, 0), point(-w, h+t, d))
+	walls.box(point(w, -h-t, 0), point(w+t, h+t, d))
+	walls.box(point(-w, -h-t, 0), point(w, -h, d))
+	walls.box(point(-w, h, 0), point(w, h+t, d))
+	frameMesh := e.mesh("Frame", &walls, 0)
+	addNode(node{Name: "Frame", Mesh: &frameMesh})
+
+	// Objects: one quad per layer, textured with the asset, stacked from the
+	// object's position towards the viewer
+	materials := make(map[scene.Ref]int)
+	for i := range doc.Objects {
+		obj := &doc.Objects[i]
+		asset, ok := assets[obj.AssetID]
+		if obj.AssetID == "" || !ok {
+			continue
+		}
+
+		index, ok := materials[obj.AssetID]
+		if !ok {
+			index = e.texturedMaterial("Asset "+string(obj.AssetID), asset.PNG)
+			materials[obj.AssetID] = index
+		}
+
+		widthCM, heightCM := obj.SizeCM(asset.Width, asset.Height)
+		var layers geometry
+		for layer := 0; layer < max(obj.Layers, 1); layer++ {
+			z := float64(layer)*obj.LayerSpacing + layerLiftCM
+			layers.quad(point(-widthCM/2, -heightCM/2, z), point(widthCM, 0, 0), point(0, heightCM, 0))
+		}
+
+		name := "Object " + string(obj.ID)
+		objectMesh := e.mesh(name, &layers, index)
+		n := node{
+			Name:        name,
+			Mesh:        &objectMesh,
+			Translation: &[3]float64{metres(obj.Position.X), metres(obj.Position.Y), metres(obj.Position.Z)},
+		}
+		if obj.Rotation != nil {
+			n.Rotation = quaternion(*obj.Rotation)
+		}
+		addNode(n)
+	}
+
+	// Lights: a point light at every LED, from the scene or else the frame
+	lighting := doc.Settings.Lighting
+	if lighting.Enabled {
+		positions := lighting.Positions
+		if len(positions) == 0 {
+			positions = frame.LEDMounts
+		}
+		color := "#ffffff"
+		if lighting.Color != "" {
+			color = lighting.Color
+		}
+		factor := colorFactor(color)
+
+		var lights []light
+		for i, p := range positions {
+			lights = append(lights, light{
+				Name:      "LED " + strconv.Itoa(i+1),
+				Type:      "point",
+				Color:     [3]float64{factor[0], factor[1], factor[2]},
+				Intensity: lighting.Intensity * candelaPerIntensity,
+			})
+			addNode(node{
+				Name:        "LED " + strconv.Itoa(i+1),
+				Translation: &[3]float64{metres(p.X), metres(p.Y), metres(p.Z)},
+				Extensions:  &nodeExtensions{Light: lightRef{Light: i}},
+			})
+		}
+		if len(lights) > 0 {
+			e.doc.ExtensionsUsed = []string{lightsPunctual}
+			e.doc.Extensions = map[string]lightsExtension{lightsPunctual: {Lights: lights}}
+		}
+	}
+
+	return e.glb()
+}
+
+// texturedMaterial adds a double-sided material textured with a PNG whose
+// transparent pixels are cut away, and returns its index
+func (e *encoder) texturedMaterial(name string, png []byte) int {
+	if len(e.doc.Samplers) == 0 {
+		e.doc.Samplers = []sampler{{
+			MagFilter: filterLinear,
+			MinFilter: filterLinearMipmap,
+			WrapS:     wrapClampToEdge,
+			WrapT:     wrapClampToEdge,
+		}}
+	}
+	e.doc.Images = append(e.doc.Images, imageRef{Name: name, BufferView: e.view(png, 0), MimeType: "image/png"})
+	e.doc.Textures = append(e.doc.Textures, texture{Sampler: 0, Source: len(e.doc.Images) - 1})
+
+	cutoff := 0.5
+	e.doc.Materials = append(e.doc.Materials, material{
+		Name: name,
+		PBRMetallicRoughness: pbrMetallicRoughness{
+			BaseColorTexture: &textureRef{Index: len(e.doc.Textures) - 1},
+			RoughnessFactor:  0.9,
+		},
+		AlphaMode:   "MASK",
+		AlphaCutoff: &cutoff,
+		DoubleSided: true,
+	})
+	return len(e.doc.Materials) - 1
+}
+
+// point converts a position in cm to metres
+func point(x, y, z float64) [3]float32 {
+	return [3]float32{float32(metres(x)), float32(metres(y)), float32(metres(z))}
+}
+
+func metres(cm float64) float64 {
+	return cm / 100
+}
+
+// quaternion converts the editor's rotation, Euler angles in radians applied
+// in XYZ order, to a glTF rotation
+func quaternion(r scene.Vector3) *[4]float64 {
+	c1, s1 := math.Cos(r.X/2), math.Sin(r.X/2)
+	c2, s2 := math.Cos(r.Y/2), math.Sin(r.Y/2)
+	c3, s3 := math.Cos(r.Z/2), math.Sin(r.Z/2)
+	return &[4]float64{
+		s1*c2*c3 + c1*s2*s3,
+		c1*s2*c3 - s1*c2*s3,
+		c1*c2*s3 + s1*s2*c3,
+		c1*c2*c3 - s1*s2*s3,
+	}
+}
+
+// colorFactor converts a validated "#rgb" or "#rrggbb" colour to a linear
+// RGBA factor
+func colorFactor(hex string) *[4]float64 {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	factor := [4]float64{1, 1, 1, 1}
+	for i := 0; i < 3 && len(hex) == 6; i++ {
+		value, err := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+		if err != nil {
+			break
+		}
+		factor[i] = linear(float64(value) / 255)
+	}
+	return &factor
+}
+
+// linear converts an sRGB channel to linear light, as glTF colours are linear
+func linear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
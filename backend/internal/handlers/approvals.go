@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// approvalTransitions lists the decisions a buyer may make from each approval
+// state. Approval is final, so it has no outgoing transitions.
+var approvalTransitions = map[string][]string{
+	models.ApprovalPending:          {models.ApprovalChangesRequested, models.ApprovalApproved},
+	models.ApprovalChangesRequested: {models.ApprovalChangesRequested, models.ApprovalApproved},
+}
+
+// errDecisionConflict is returned when the link's approval state changed while
+// a decision was being recorded
+var errDecisionConflict = errors.New("approval state changed concurrently")
+
+// DecideSharedLink handles POST /api/shared/:token/decision - record the buyer's
+// approval or change request for the project revision they reviewed
+func (h *SharedLinkHandler) DecideSharedLink(c *gin.Context) {
+	sharedLink, ok := findAccessibleSharedLink(c, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	var req models.SharedLinkDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   "name cannot be blank",
+		})
+		return
+	}
+
+	if !approvalTransitionAllowed(sharedLink.ApprovalStatus, req.Decision) {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Decision not allowed",
+			Error:   "This shared link has already been " + strings.ReplaceAll(sharedLink.ApprovalStatus, "_", " "),
+		})
+		return
+	}
+
+	now := time.Now()
+	var project models.Project
+	err := config.GetDB().Transaction(func(tx *gorm.DB) error {
+		// Read the head revision inside the transaction so the recorded
+		// revision is the one current when the decision is stored
+		if err := tx.Select("id", "version").First(&project, sharedLink.ProjectID).Error; err != nil {
+			return err
+		}
+		if req.Revision != project.Version {
+			return errVersionConflict
+		}
+
+		updates := map[string]interface{}{
+			"approval_status":   req.Decision,
+			"approved_revision": nil,
+			"decided_at":        now,
+			"decided_by":        name,
+		}
+		if req.Decision == models.ApprovalApproved {
+			updates["approved_revision"] = project.Version
+		}
+
+		result := tx.Model(&models.SharedLink{}).
+			Where("id = ? AND approval_status = ?", sharedLink.ID, sharedLink.ApprovalStatus).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errDecisionConflict
+		}
+
+		return tx.Create(&models.ApprovalDecision{
+			ProjectID:    project.ID,
+			SharedLinkID: &sharedLink.ID,
+			Decision:     req.Decision,
+			Revision:     project.Version,
+			Name:         name,
+			Note:         strings.TrimSpace(req.Note),
+			IPHash:       hashClientIP(c.ClientIP()),
+			UserAgent:    truncate(c.Request.UserAgent(), maxAccessFieldLength),
+		}).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errVersionConflict):
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Message: "Project has changed",
+				Error:   "The project was updated after you reviewed it; reload it before deciding",
+				Details: map[string]int{"current_revision": project.Version},
+			})
+		case errors.Is(err, errDecisionConflict):
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Message: "Decision not allowed",
+				Error:   "Another decision was recorded at the same time; reload and try again",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to record decision",
+				Error:   err.Error(),
+			})
+		}
+		return
+	}
+
+	sharedLink.ApprovalStatus = req.Decision
+	sharedLink.ApprovedRevision = nil
+	if req.Decision == models.ApprovalApproved {
+		sharedLink.ApprovedRevision = &project.Version
+	}
+	sharedLink.DecidedAt = &now
+	sharedLink.DecidedBy = name
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Decision recorded successfully",
+		Data:    buildBuyerLink(sharedLink),
+	})
+}
+
+// GetProjectDecisions handles GET /api/projects/:id/decisions - list every buyer
+// decision recorded for a project, newest first
+func (h *SharedLinkHandler) GetProjectDecisions(c *gin.Context) {
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	var decisions []models.ApprovalDecision
+	if err := config.GetDB().Where("project_id = ?", project.ID).
+		Order("created_at DESC").
+		Find(&decisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch decisions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Decisions fetched successfully",
+		Data:    decisions,
+	})
+}
+
+// approvalTransitionAllowed reports whether a buyer may make decision while the
+// link is in state from
+func approvalTransitionAllowed(from, decision string) bool {
+	for _, allowed := range approvalTransitions[from] {
+		if allowed == decision {
+			return true
+		}
+	}
+	return false
+}
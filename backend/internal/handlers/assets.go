@@ -1,28 +1,49 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"image"
+	"io"
+	"log"
 	"net/http"
-	"path/filepath"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/auth"
+	"scrapyuk-backend/internal/imaging"
 	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
+)
+
+// maxAssetSize is the largest accepted upload in bytes
+const maxAssetSize = 10 * 1024 * 1024
+
+// Lifetimes of signed asset URLs. Seven days is also the presign limit of S3-compatible storage.
+const (
+	defaultSignedURLTTL = 15 * time.Minute
+	maxSignedURLTTL     = 7 * 24 * time.Hour
 )
 
 // AssetHandler handles asset-related HTTP requests
-type AssetHandler struct{}
+type AssetHandler struct {
+	// requireTransparency rejects opaque uploads instead of flagging them
+	requireTransparency bool
+}
 
 // NewAssetHandler creates a new asset handler
 func NewAssetHandler() *AssetHandler {
-	return &AssetHandler{}
+	return &AssetHandler{
+		requireTransparency: os.Getenv("ASSET_REQUIRE_TRANSPARENCY") == "true",
+	}
 }
 
 // GetProjectAssets handles GET /api/projects/:id/assets - list project assets
@@ -41,7 +62,7 @@ func (h *AssetHandler) GetProjectAssets(c *gin.Context) {
 
 	// Verify project exists
 	var project models.Project
-	if err := db.First(&project, projectID).Error; err != nil {
+	if err := db.Scopes(ownedProjects(c)).First(&project, projectID).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Project not found",
@@ -52,7 +73,7 @@ func (h *AssetHandler) GetProjectAssets(c *gin.Context) {
 
 	// Get project assets
 	var assets []models.Asset
-	if err := db.Where("project_id = ?", projectID).Order("uploaded_at DESC").Find(&assets).Error; err != nil {
+	if err := db.Where("project_id = ?", projectID).Preload("Renditions").Order("uploaded_at DESC").Find(&assets).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Message: "Failed to fetch assets",
@@ -84,7 +105,7 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 
 	// Verify project exists
 	var project models.Project
-	if err := db.First(&project, projectID).Error; err != nil {
+	if err := db.Scopes(ownedProjects(c)).First(&project, projectID).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Project not found",
@@ -93,12 +114,12 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 		return
 	}
 
-	// Check if MinIO is available
-	if !config.IsMinIOAvailable() {
+	// Check if storage is available
+	if !config.IsStorageAvailable() {
 		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
 			Success: false,
 			Message: "File storage service unavailable",
-			Error:   "MinIO storage is not configured or unavailable",
+			Error:   "Storage backend is not configured or unavailable",
 		})
 		return
 	}
@@ -115,19 +136,30 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// Validate file type (only PNG allowed)
 	filename := header.Filename
-	if !isPNGFile(filename) {
+
+	// Validate file size (max 10MB)
+	if header.Size > maxAssetSize {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
-			Message: "Invalid file type",
-			Error:   "Only PNG files are allowed",
+			Message: "File too large",
+			Error:   "File size must be less than 10MB",
 		})
 		return
 	}
 
-	// Validate file size (max 10MB)
-	if header.Size > 10*1024*1024 {
+	// Read the file so its content can be validated and stored. The limit
+	// guards against multipart headers that under-report the size.
+	data, err := io.ReadAll(io.LimitReader(file, maxAssetSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Failed to read uploaded file",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if len(data) > maxAssetSize {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Message: "File too large",
@@ -136,19 +168,37 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 		return
 	}
 
-	// Generate unique filename
-	uniqueID := uuid.New().String()
-	ext := filepath.Ext(filename)
-	objectName := fmt.Sprintf("projects/%d/assets/%s%s", projectID, uniqueID, ext)
+	// Validate file type by content (only PNG allowed)
+	img, info, err := imaging.InspectPNG(data)
+	if err != nil {
+		message := "Invalid image"
+		if errors.Is(err, imaging.ErrNotPNG) {
+			message = "Invalid file type"
+		}
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: message,
+			Error:   err.Error(),
+		})
+		return
+	}
 
-	// Upload to MinIO
-	minioClient := config.GetMinIOClient()
-	bucketName := config.GetBucketName()
+	// Transparent PNGs are expected (US-06); opaque ones are flagged or rejected
+	var warnings []string
+	if !info.HasAlpha {
+		if h.requireTransparency || c.Query("strict") == "true" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Image has no transparency",
+				Error:   "Only PNG images with a transparent background are allowed",
+			})
+			return
+		}
+		warnings = append(warnings, "Image has no transparent pixels and will render as a solid rectangle")
+	}
 
 	ctx := context.Background()
-	_, err = minioClient.PutObject(ctx, bucketName, objectName, file, header.Size, minio.PutObjectOptions{
-		ContentType: "image/png",
-	})
+	asset, uploaded, err := storeAssetFiles(ctx, uint(projectID), filename, data, img, info)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -158,17 +208,10 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 		return
 	}
 
-	// Save asset record to database
-	asset := models.Asset{
-		ProjectID:  uint(projectID),
-		Filename:   filename,
-		FilePath:   objectName,
-		UploadedAt: time.Now(),
-	}
-
+	// Save asset record (and its renditions) to database
 	if err := db.Create(&asset).Error; err != nil {
-		// If database save fails, try to delete the uploaded file
-		minioClient.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{})
+		// If database save fails, try to delete the uploaded files
+		deleteStoredKeys(ctx, uploaded)
 
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -178,8 +221,7 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 		return
 	}
 
-	// Generate file URL for response
-	asset.FilePath = fmt.Sprintf("/api/assets/%s", objectName)
+	asset.Warnings = warnings
 
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success: true,
@@ -188,6 +230,65 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 	})
 }
 
+// storeAssetFiles stores a validated PNG and its renditions under the project's
+// asset prefix and returns the unsaved asset record with the keys written, so
+// the caller can remove them if saving the record fails
+func storeAssetFiles(ctx context.Context, projectID uint, filename string, data []byte, img image.Image, info imaging.PNGInfo) (models.Asset, []string, error) {
+	renditions, err := imaging.GenerateRenditions(img, imaging.RenditionSizes)
+	if err != nil {
+		return models.Asset{}, nil, fmt.Errorf("generating renditions: %w", err)
+	}
+
+	// Generate unique filename
+	uniqueID := uuid.New().String()
+	objectName := fmt.Sprintf("projects/%d/assets/%s.png", projectID, uniqueID)
+
+	// Upload original and renditions to storage
+	store := config.GetStorage()
+	if err := store.Put(ctx, objectName, bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
+		return models.Asset{}, nil, err
+	}
+	uploaded := []string{objectName}
+
+	assetRenditions := make([]models.AssetRendition, 0, len(renditions))
+	for _, rendition := range renditions {
+		key := fmt.Sprintf("projects/%d/assets/%s_%d.png", projectID, uniqueID, rendition.Size)
+		if err := store.Put(ctx, key, bytes.NewReader(rendition.Data), int64(len(rendition.Data)), "image/png"); err != nil {
+			deleteStoredKeys(ctx, uploaded)
+			return models.Asset{}, nil, fmt.Errorf("uploading rendition: %w", err)
+		}
+		uploaded = append(uploaded, key)
+
+		assetRenditions = append(assetRenditions, models.AssetRendition{
+			Size:     rendition.Size,
+			Width:    rendition.Width,
+			Height:   rendition.Height,
+			FilePath: key,
+		})
+	}
+
+	return models.Asset{
+		ProjectID:  projectID,
+		Filename:   filename,
+		FilePath:   objectName,
+		Size:       int64(len(data)),
+		Width:      info.Width,
+		Height:     info.Height,
+		HasAlpha:   info.HasAlpha,
+		UploadedAt: time.Now(),
+		Renditions: assetRenditions,
+	}, uploaded, nil
+}
+
+// deleteStoredKeys removes files written before a failed operation. Failures
+// are ignored; the orphaned asset sweep removes anything left behind.
+func deleteStoredKeys(ctx context.Context, keys []string) {
+	store := config.GetStorage()
+	for _, key := range keys {
+		store.Delete(ctx, key)
+	}
+}
+
 // DeleteAsset handles DELETE /api/assets/:id - delete an asset
 func (h *AssetHandler) DeleteAsset(c *gin.Context) {
 	db := config.GetDB()
@@ -203,7 +304,7 @@ func (h *AssetHandler) DeleteAsset(c *gin.Context) {
 	}
 
 	var asset models.Asset
-	if err := db.First(&asset, assetID).Error; err != nil {
+	if err := db.Scopes(ownedThroughProject(c, "assets")).Preload("Renditions").First(&asset, assetID).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Asset not found",
@@ -212,21 +313,23 @@ func (h *AssetHandler) DeleteAsset(c *gin.Context) {
 		return
 	}
 
-	// Delete from MinIO if available
-	if config.IsMinIOAvailable() {
-		minioClient := config.GetMinIOClient()
-		bucketName := config.GetBucketName()
-		ctx := context.Background()
+	// Delete original and renditions from storage if available
+	if config.IsStorageAvailable() {
+		keys := []string{asset.FilePath}
+		for _, rendition := range asset.Renditions {
+			keys = append(keys, rendition.FilePath)
+		}
 
-		err = minioClient.RemoveObject(ctx, bucketName, asset.FilePath, minio.RemoveObjectOptions{})
-		if err != nil {
-			// Log error but continue with database deletion
-			fmt.Printf("Failed to delete file from MinIO: %v\n", err)
+		for _, key := range keys {
+			if err := config.GetStorage().Delete(context.Background(), key); err != nil {
+				// Log error but continue with database deletion
+				log.Printf("Failed to delete %s from storage: %v", key, err)
+			}
 		}
 	}
 
-	// Delete from database
-	if err := db.Delete(&asset).Error; err != nil {
+	// Delete from database along with rendition records
+	if err := db.Select("Renditions").Delete(&asset).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Message: "Failed to delete asset",
@@ -241,9 +344,11 @@ func (h *AssetHandler) DeleteAsset(c *gin.Context) {
 	})
 }
 
-// ServeAsset handles GET /api/assets/* - serve asset files
+// ServeAsset handles GET /api/assets/* - serve asset files. Assets are private:
+// the caller must be the owning creator, present a shared link token for the
+// asset's project (?share= or X-Share-Token), or use a signed URL from GetAssetURL.
 func (h *AssetHandler) ServeAsset(c *gin.Context) {
-	if !config.IsMinIOAvailable() {
+	if !config.IsStorageAvailable() {
 		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
 			Success: false,
 			Message: "File storage service unavailable",
@@ -251,7 +356,7 @@ func (h *AssetHandler) ServeAsset(c *gin.Context) {
 		return
 	}
 
-	objectName := c.Param("filepath")
+	objectName := strings.TrimPrefix(c.Param("filepath"), "/")
 	if objectName == "" {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
@@ -260,44 +365,207 @@ func (h *AssetHandler) ServeAsset(c *gin.Context) {
 		return
 	}
 
-	minioClient := config.GetMinIOClient()
-	bucketName := config.GetBucketName()
-	ctx := context.Background()
+	if match := cutlinePath.FindStringSubmatch(objectName); match != nil {
+		h.serveCutline(c, match[1], match[2])
+		return
+	}
 
-	// Get object from MinIO
-	object, err := minioClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
-	if err != nil {
+	// Signed URLs carry their own authorization
+	if signature := c.Query("signature"); signature != "" {
+		if !auth.VerifyAssetSignature(config.GetJWTSecret(), objectName, c.Query("expires"), signature) {
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Message: "Access denied",
+				Error:   "The signed URL is invalid or has expired",
+			})
+			return
+		}
+		streamStoredObject(c, objectName, "private, max-age=300")
+		return
+	}
+
+	// Only files belonging to an asset are served, never arbitrary keys
+	var projectIDs []uint
+	if err := config.GetDB().Raw(
+		`SELECT project_id FROM assets WHERE file_path = ?
+		UNION SELECT assets.project_id FROM asset_renditions
+		JOIN assets ON assets.id = asset_renditions.asset_id
+		WHERE asset_renditions.file_path = ?`,
+		objectName, objectName,
+	).Scan(&projectIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch asset",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if len(projectIDs) == 0 {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "File not found",
+		})
+		return
+	}
+	projectID := projectIDs[0]
+
+	if currentUserID(c) != 0 {
+		var owned int64
+		if err := config.GetDB().Model(&models.Project{}).Scopes(ownedProjects(c)).
+			Where("id = ?", projectID).Count(&owned).Error; err == nil && owned > 0 {
+			streamStoredObject(c, objectName, "private, max-age=3600")
+			return
+		}
+	}
+
+	shareToken := c.Query("share")
+	if shareToken == "" {
+		shareToken = c.GetHeader("X-Share-Token")
+	}
+	if shareToken != "" {
+		sharedLink, ok := findAccessibleSharedLink(c, shareToken)
+		if !ok {
+			return
+		}
+		if sharedLink.ProjectID == projectID {
+			streamStoredObject(c, objectName, "private, max-age=3600")
+			return
+		}
+	}
+
+	// Other creators' files look the same as missing ones
+	if currentUserID(c) != 0 || shareToken != "" {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "File not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, models.APIResponse{
+		Success: false,
+		Message: "Authentication required",
+		Error:   "Sign in, or use a shared link or signed URL to access this asset",
+	})
+}
+
+// GetAssetURL handles GET /api/projects/:id/assets/:assetId/url - issue a
+// time-limited URL for an asset file. ?size= selects a rendition, ?expires_in=
+// sets the lifetime in seconds and ?direct=true asks the storage backend for a
+// presigned URL instead of a signed API URL.
+func (h *AssetHandler) GetAssetURL(c *gin.Context) {
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	var asset models.Asset
+	if err := config.GetDB().Preload("Renditions").
+		Where("project_id = ?", project.ID).
+		First(&asset, c.Param("assetId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Asset not found",
 			Error:   err.Error(),
 		})
 		return
 	}
-	defer object.Close()
 
-	// Get object info for content type and size
-	objectInfo, err := object.Stat()
+	key := asset.FilePath
+	if size := c.Query("size"); size != "" {
+		key = ""
+		for _, rendition := range asset.Renditions {
+			if strconv.Itoa(rendition.Size) == size {
+				key = rendition.FilePath
+			}
+		}
+		if key == "" {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Rendition not found",
+				Error:   "No rendition of size " + size + " exists for this asset",
+			})
+			return
+		}
+	}
+
+	expiresIn := defaultSignedURLTTL
+	if value := c.Query("expires_in"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 1 || time.Duration(seconds)*time.Second > maxSignedURLTTL {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid expires_in",
+				Error:   fmt.Sprintf("expires_in must be between 1 and %d seconds", int(maxSignedURLTTL.Seconds())),
+			})
+			return
+		}
+		expiresIn = time.Duration(seconds) * time.Second
+	}
+	expiresAt := time.Now().Add(expiresIn)
+
+	var url string
+	if c.Query("direct") == "true" {
+		if !config.IsStorageAvailable() {
+			c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+				Success: false,
+				Message: "File storage service unavailable",
+			})
+			return
+		}
+
+		presigned, err := config.GetStorage().PresignGet(c.Request.Context(), key, expiresIn)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, storage.ErrPresignNotSupported) {
+				status = http.StatusNotImplemented
+			}
+			c.JSON(status, models.APIResponse{
+				Success: false,
+				Message: "Failed to presign asset URL",
+				Error:   err.Error(),
+			})
+			return
+		}
+		url = presigned
+	} else {
+		url = models.AssetURL(key) + "?expires=" + strconv.FormatInt(expiresAt.Unix(), 10) +
+			"&signature=" + auth.SignAssetKey(config.GetJWTSecret(), key, expiresAt)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Asset URL issued successfully",
+		Data: map[string]interface{}{
+			"url":        url,
+			"expires_at": time.Unix(expiresAt.Unix(), 0).UTC(),
+		},
+	})
+}
+
+// streamStoredObject writes the stored object with the given key as the response
+func streamStoredObject(c *gin.Context, key, cacheControl string) {
+	// Get object and its info from storage
+	object, objectInfo, err := config.GetStorage().Get(c.Request.Context(), key)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, models.APIResponse{
 			Success: false,
 			Message: "File not found",
 			Error:   err.Error(),
 		})
 		return
 	}
+	defer object.Close()
 
 	// Set appropriate headers
 	c.Header("Content-Type", objectInfo.ContentType)
 	c.Header("Content-Length", strconv.FormatInt(objectInfo.Size, 10))
-	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("Cache-Control", cacheControl)
 
 	// Stream file content
 	c.DataFromReader(http.StatusOK, objectInfo.Size, objectInfo.ContentType, object, nil)
 }
-
-// isPNGFile checks if the filename has a PNG extension
-func isPNGFile(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	return ext == ".png"
-}
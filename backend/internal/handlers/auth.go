@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/auth"
+	"scrapyuk-backend/internal/middleware"
+	"scrapyuk-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler handles creator authentication requests
+type AuthHandler struct{}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler() *AuthHandler {
+	return &AuthHandler{}
+}
+
+// Login handles POST /api/auth/login - authenticate a creator and start a session
+func (h *AuthHandler) Login(c *gin.Context) {
+	db := config.GetDB()
+
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	if err := db.Where("email = ?", strings.ToLower(strings.TrimSpace(req.Email))).First(&user).Error; err != nil ||
+		!auth.CheckPassword(user.PasswordHash, req.Password) {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Invalid credentials",
+			Error:   "Email or password is incorrect",
+		})
+		return
+	}
+
+	token, tokenID, expiresAt, err := auth.IssueToken(config.GetJWTSecret(), user.ID, config.GetSessionTTL())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to create session",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	session := models.Session{
+		UserID:    user.ID,
+		TokenID:   tokenID,
+		ExpiresAt: expiresAt,
+	}
+	if err := db.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to create session",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	setSessionCookie(c, token, int(time.Until(expiresAt).Seconds()))
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Logged in successfully",
+		Data: models.LoginResponse{
+			User:      user,
+			Token:     token,
+			ExpiresAt: expiresAt,
+		},
+	})
+}
+
+// Logout handles POST /api/auth/logout - revoke the current session
+func (h *AuthHandler) Logout(c *gin.Context) {
+	db := config.GetDB()
+
+	session := middleware.CurrentSession(c)
+	if session != nil {
+		now := time.Now()
+		if err := db.Model(session).Update("revoked_at", &now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to revoke session",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	setSessionCookie(c, "", -1)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Logged out successfully",
+	})
+}
+
+// Me handles GET /api/auth/me - return the authenticated creator
+func (h *AuthHandler) Me(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "User fetched successfully",
+		Data:    middleware.CurrentUser(c),
+	})
+}
+
+// setSessionCookie writes (or clears, when maxAge is negative) the session cookie
+func setSessionCookie(c *gin.Context, token string, maxAge int) {
+	secure := os.Getenv("GIN_MODE") == "release"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(config.SessionCookieName, token, maxAge, "/", "", secure, true)
+}
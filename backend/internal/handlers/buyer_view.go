@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Kinds of identifier hidden behind opaque buyer references
+const (
+	refKindAsset  = "asset"
+	refKindObject = "object"
+)
+
+// buildBuyerProject converts a project into the sanitized view served through
+// the given shared link. Only assets placed in the scene are included.
+func buildBuyerProject(project *models.Project, link *models.SharedLink) (*models.BuyerProject, error) {
+	doc, err := scene.Parse(project.ProjectData)
+	if err != nil {
+		return nil, err
+	}
+	doc.Objects = append(doc.Objects, sceneObjectsFromRows(project.Objects)...)
+
+	assetsByID := make(map[string]models.Asset, len(project.Assets))
+	for _, asset := range project.Assets {
+		assetsByID[strconv.FormatUint(uint64(asset.ID), 10)] = asset
+	}
+
+	view := &models.BuyerProject{
+		Title:     project.Title,
+		FrameSize: project.FrameSize,
+		Revision:  project.Version,
+		Scene:     *doc,
+		Assets:    []models.BuyerAsset{},
+		UpdatedAt: project.UpdatedAt,
+	}
+	if frame := project.FrameTemplate; frame != nil {
+		view.Frame = &models.BuyerFrame{
+			Name:         frame.Name,
+			WidthCM:      frame.WidthCM,
+			HeightCM:     frame.HeightCM,
+			DepthCM:      frame.DepthCM,
+			WallMaterial: frame.WallMaterial,
+			LEDMounts:    frame.LEDMounts,
+		}
+	}
+
+	// Copy the objects so the parsed document is left untouched
+	view.Scene.Objects = make([]scene.Object, len(doc.Objects))
+	included := map[string]bool{}
+	for i, object := range doc.Objects {
+		object.ID = scene.Ref(buyerRef(link.Token, refKindObject, string(object.ID)))
+
+		asset, ok := assetsByID[string(object.AssetID)]
+		if !ok {
+			// Never leak references to assets that are missing from the project
+			object.AssetID = ""
+			view.Scene.Objects[i] = object
+			continue
+		}
+
+		ref := buyerRef(link.Token, refKindAsset, string(object.AssetID))
+		object.AssetID = scene.Ref(ref)
+		view.Scene.Objects[i] = object
+
+		if !included[ref] {
+			included[ref] = true
+			view.Assets = append(view.Assets, buildBuyerAsset(link.Token, ref, &asset))
+		}
+	}
+
+	return view, nil
+}
+
+// buildBuyerAsset describes an asset with URLs scoped to the shared link
+func buildBuyerAsset(linkToken, ref string, asset *models.Asset) models.BuyerAsset {
+	url := "/api/shared/" + linkToken + "/assets/" + ref
+	buyerAsset := models.BuyerAsset{
+		ID:           ref,
+		Width:        asset.Width,
+		Height:       asset.Height,
+		HasAlpha:     asset.HasAlpha,
+		URL:          url,
+		ThumbnailURL: url,
+	}
+
+	smallest := 0
+	for _, rendition := range asset.Renditions {
+		renditionURL := url + "?size=" + strconv.Itoa(rendition.Size)
+		buyerAsset.Renditions = append(buyerAsset.Renditions, models.BuyerRendition{
+			Size:   rendition.Size,
+			Width:  rendition.Width,
+			Height: rendition.Height,
+			URL:    renditionURL,
+		})
+		if smallest == 0 || rendition.Size < smallest {
+			smallest = rendition.Size
+			buyerAsset.ThumbnailURL = renditionURL
+		}
+	}
+
+	return buyerAsset
+}
+
+// buildBuyerLink describes the shared link without its internal fields
+func buildBuyerLink(link *models.SharedLink) models.BuyerLink {
+	buyerLink := models.BuyerLink{
+		ExpiresAt:        link.ExpiresAt,
+		ApprovalStatus:   link.ApprovalStatus,
+		ApprovedRevision: link.ApprovedRevision,
+		DecidedAt:        link.DecidedAt,
+	}
+	if link.MaxViews != nil {
+		remaining := *link.MaxViews - link.ViewCount
+		if remaining < 0 {
+			remaining = 0
+		}
+		buyerLink.ViewsRemaining = &remaining
+	}
+	return buyerLink
+}
+
+// buyerRef derives the opaque identifier a shared link's viewers see in place
+// of an internal ID. It is stable for a link but differs between links, so
+// references cannot be correlated across links or mapped back without the
+// server secret.
+func buyerRef(linkToken, kind, id string) string {
+	mac := hmac.New(sha256.New, config.GetJWTSecret())
+	mac.Write([]byte(linkToken + "/" + kind + "/" + id))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// refMatches reports whether ref is the buyer reference for the given internal ID
+func refMatches(linkToken, kind, id, ref string) bool {
+	return hmac.Equal([]byte(buyerRef(linkToken, kind, id)), []byte(ref))
+}
+
+// GetSharedAsset handles GET /api/shared/:token/assets/:ref - serve an asset of a
+// shared project by its buyer reference. ?size= selects a rendition.
+func (h *SharedLinkHandler) GetSharedAsset(c *gin.Context) {
+	if !config.IsStorageAvailable() {
+		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Message: "File storage service unavailable",
+		})
+		return
+	}
+
+	sharedLink, ok := findAccessibleSharedLink(c, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	var assets []models.Asset
+	if err := config.GetDB().Preload("Renditions").Where("project_id = ?", sharedLink.ProjectID).Find(&assets).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch asset",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var asset *models.Asset
+	for i := range assets {
+		if refMatches(sharedLink.Token, refKindAsset, strconv.FormatUint(uint64(assets[i].ID), 10), c.Param("ref")) {
+			asset = &assets[i]
+			break
+		}
+	}
+	if asset == nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Asset not found",
+		})
+		return
+	}
+
+	key := asset.FilePath
+	if size := c.Query("size"); size != "" {
+		key = ""
+		for _, rendition := range asset.Renditions {
+			if strconv.Itoa(rendition.Size) == size {
+				key = rendition.FilePath
+			}
+		}
+		if key == "" {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Rendition not found",
+				Error:   "No rendition of size " + size + " exists for this asset",
+			})
+			return
+		}
+	}
+
+	// Viewers may be restricted, so shared caches must not keep a copy
+	streamStoredObject(c, key, "private, max-age=3600")
+}
@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/middleware"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// refKindComment marks opaque buyer references to comments
+const refKindComment = "comment"
+
+// CommentHandler handles buyer feedback and creator replies
+type CommentHandler struct{}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler() *CommentHandler {
+	return &CommentHandler{}
+}
+
+// CreateBuyerComment handles POST /api/shared/:token/comments - leave feedback on a
+// shared project, optionally pinned to a scene object or position, or reply to
+// an existing thread with reply_to
+func (h *CommentHandler) CreateBuyerComment(c *gin.Context) {
+	db := config.GetDB()
+
+	sharedLink, ok := findAccessibleSharedLink(c, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	var req models.CommentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	comment := models.Comment{
+		ProjectID:    sharedLink.ProjectID,
+		SharedLinkID: &sharedLink.ID,
+		AuthorName:   strings.TrimSpace(req.Name),
+		Message:      strings.TrimSpace(req.Message),
+		Position:     req.Position,
+	}
+	if comment.AuthorName == "" || comment.Message == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   "name and message cannot be blank",
+		})
+		return
+	}
+
+	if req.Position != nil {
+		if err := validateVector("position", req.Position); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid request data",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	var parent *models.Comment
+	if req.ReplyTo != "" {
+		if req.ObjectID != "" || req.Position != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid request data",
+				Error:   "Replies cannot be pinned to an object or position",
+			})
+			return
+		}
+
+		var threads []models.Comment
+		if err := db.Where("shared_link_id = ? AND parent_id IS NULL", sharedLink.ID).Find(&threads).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to create comment",
+				Error:   err.Error(),
+			})
+			return
+		}
+		for i := range threads {
+			if refMatches(sharedLink.Token, refKindComment, strconv.FormatUint(uint64(threads[i].ID), 10), req.ReplyTo) {
+				parent = &threads[i]
+				break
+			}
+		}
+		if parent == nil {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Comment not found",
+				Error:   "reply_to does not match a comment on this link",
+			})
+			return
+		}
+		comment.ParentID = &parent.ID
+	}
+
+	// Buyers see opaque object IDs, so map the pin back to the scene object
+	if req.ObjectID != "" {
+		var project models.Project
+		if err := db.Select("id", "project_data").First(&project, sharedLink.ProjectID).Error; err != nil {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Project not found",
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		doc, err := scene.Parse(project.ProjectData)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+				Success: false,
+				Message: "Project data could not be parsed",
+				Error:   err.Error(),
+			})
+			return
+		}
+		if err := appendObjectRows(db, project.ID, doc); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to create comment",
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		for _, object := range doc.Objects {
+			if refMatches(sharedLink.Token, refKindObject, string(object.ID), req.ObjectID) {
+				comment.ObjectID = string(object.ID)
+				break
+			}
+		}
+		if comment.ObjectID == "" {
+			c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+				Success: false,
+				Message: "Invalid request data",
+				Error:   "object_id does not match an object in the scene",
+			})
+			return
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		// A buyer following up reopens a resolved thread
+		if parent != nil && parent.ResolvedAt != nil {
+			return tx.Model(parent).Updates(map[string]interface{}{"resolved_at": nil, "resolved_by_id": nil}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to create comment",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Comment created successfully",
+		Data:    buildBuyerComment(sharedLink.Token, &comment),
+	})
+}
+
+// GetBuyerComments handles GET /api/shared/:token/comments - list the comment
+// threads left through a shared link, with creator replies
+func (h *CommentHandler) GetBuyerComments(c *gin.Context) {
+	sharedLink, ok := findAccessibleSharedLink(c, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	var threads []models.Comment
+	if err := config.GetDB().
+		Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("shared_link_id = ? AND parent_id IS NULL", sharedLink.ID).
+		Order("created_at ASC").
+		Find(&threads).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch comments",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	comments := make([]models.BuyerComment, len(threads))
+	for i := range threads {
+		comments[i] = buildBuyerComment(sharedLink.Token, &threads[i])
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Comments fetched successfully",
+		Data:    comments,
+	})
+}
+
+// GetProjectComments handles GET /api/projects/:id/comments - list a project's
+// comment threads, newest first. ?status=open or ?status=resolved filters them.
+func (h *CommentHandler) GetProjectComments(c *gin.Context) {
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	query := config.GetDB().
+		Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("project_id = ? AND parent_id IS NULL", project.ID)
+
+	switch c.Query("status") {
+	case "":
+	case "open":
+		query = query.Where("resolved_at IS NULL")
+	case "resolved":
+		query = query.Where("resolved_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid status",
+			Error:   "status must be \"open\" or \"resolved\"",
+		})
+		return
+	}
+
+	var threads []models.Comment
+	if err := query.Order("created_at DESC").Find(&threads).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch comments",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Comments fetched successfully",
+		Data:    threads,
+	})
+}
+
+// ReplyToComment handles POST /api/projects/:id/comments/:commentId/replies - add
+// a creator reply to a comment thread
+func (h *CommentHandler) ReplyToComment(c *gin.Context) {
+	thread, ok := findProjectThread(c)
+	if !ok {
+		return
+	}
+
+	var req models.CommentReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   "message cannot be blank",
+		})
+		return
+	}
+
+	user := middleware.CurrentUser(c)
+	reply := models.Comment{
+		ProjectID:    thread.ProjectID,
+		SharedLinkID: thread.SharedLinkID,
+		ParentID:     &thread.ID,
+		AuthorID:     &user.ID,
+		AuthorName:   user.Name,
+		Message:      message,
+	}
+
+	if err := config.GetDB().Create(&reply).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to create reply",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Reply created successfully",
+		Data:    reply,
+	})
+}
+
+// ResolveComment handles POST /api/projects/:id/comments/:commentId/resolve - mark
+// a comment thread as resolved
+func (h *CommentHandler) ResolveComment(c *gin.Context) {
+	h.setResolved(c, true)
+}
+
+// ReopenComment handles POST /api/projects/:id/comments/:commentId/reopen - mark
+// a resolved comment thread as open again
+func (h *CommentHandler) ReopenComment(c *gin.Context) {
+	h.setResolved(c, false)
+}
+
+// setResolved updates the resolution state of the thread identified by the route
+func (h *CommentHandler) setResolved(c *gin.Context, resolved bool) {
+	thread, ok := findProjectThread(c)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{"resolved_at": nil, "resolved_by_id": nil}
+	if resolved {
+		userID := currentUserID(c)
+		updates = map[string]interface{}{"resolved_at": time.Now(), "resolved_by_id": userID}
+	}
+
+	db := config.GetDB()
+	if err := db.Model(thread).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to update comment",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := db.Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(thread, thread.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch comment",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	message := "Comment reopened successfully"
+	if resolved {
+		message = "Comment resolved successfully"
+	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    thread,
+	})
+}
+
+// findProjectThread loads the comment thread identified by the :commentId route
+// parameter within the creator's project :id. Replies resolve to their thread.
+// On failure it writes the error response and returns false.
+func findProjectThread(c *gin.Context) (*models.Comment, bool) {
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return nil, false
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid comment ID",
+			Error:   "Comment ID must be a valid number",
+		})
+		return nil, false
+	}
+
+	db := config.GetDB()
+
+	var comment models.Comment
+	if err := db.Where("project_id = ?", project.ID).First(&comment, commentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Comment not found",
+			Error:   err.Error(),
+		})
+		return nil, false
+	}
+
+	if comment.ParentID != nil {
+		if err := db.First(&comment, *comment.ParentID).Error; err != nil {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Comment not found",
+				Error:   err.Error(),
+			})
+			return nil, false
+		}
+	}
+
+	return &comment, true
+}
+
+// buildBuyerComment converts a comment and its replies into the buyer view
+func buildBuyerComment(linkToken string, comment *models.Comment) models.BuyerComment {
+	buyerComment := models.BuyerComment{
+		ID:         buyerRef(linkToken, refKindComment, strconv.FormatUint(uint64(comment.ID), 10)),
+		AuthorName: comment.AuthorName,
+		IsCreator:  comment.AuthorID != nil,
+		Message:    comment.Message,
+		Position:   comment.Position,
+		Resolved:   comment.ResolvedAt != nil,
+		CreatedAt:  comment.CreatedAt,
+	}
+	if comment.ObjectID != "" {
+		buyerComment.ObjectID = buyerRef(linkToken, refKindObject, comment.ObjectID)
+	}
+	for i := range comment.Replies {
+		buyerComment.Replies = append(buyerComment.Replies, buildBuyerComment(linkToken, &comment.Replies[i]))
+	}
+	return buyerComment
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/nesting"
+	"scrapyuk-backend/internal/production"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bounds of the cut sheet layout parameters, in centimetres
+const (
+	minSheetSize = 5.0
+	maxSheetSize = 200.0
+	maxMargin    = 5.0
+	maxBleed     = 1.0
+)
+
+// GetCutSheets handles GET /api/projects/:id/cut-sheets - nest every layer of a
+// project onto paper sheets. Returns the layout as JSON, one sheet as SVG
+// (?format=svg&page=N) or every sheet as a PDF (?format=pdf).
+func (h *ProjectHandler) GetCutSheets(c *gin.Context) {
+	opts, ok := cutSheetOptions(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "svg" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid format",
+			Error:   "format must be json, svg or pdf",
+		})
+		return
+	}
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+	if err := config.GetDB().Preload("FrameTemplate").Preload("Assets").Preload("Objects", orderedObjects).First(project, project.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	plan, ok := buildProductionPlan(c, project, production.DefaultDPI)
+	if !ok {
+		return
+	}
+	layout := nestLayers(plan, opts)
+
+	switch format {
+	case "svg":
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 || page > layout.SheetCount {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Sheet not found",
+				Error:   fmt.Sprintf("The layout has %d sheet(s)", layout.SheetCount),
+			})
+			return
+		}
+		// The router defaults every response to JSON
+		c.Header("Content-Type", "image/svg+xml")
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s-%d-sheet-%d.svg"`, downloadName(project), project.ID, page))
+		c.Data(http.StatusOK, "image/svg+xml", layout.SVG(&layout.Sheets[page-1]))
+	case "pdf":
+		if layout.SheetCount == 0 {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Sheet not found",
+				Error:   "The project has no layers to lay out",
+			})
+			return
+		}
+		c.Header("Content-Type", "application/pdf")
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s-%d-cut-sheets.pdf"`, downloadName(project), project.ID))
+		c.Data(http.StatusOK, "application/pdf", layout.PDF())
+	default:
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Cut sheets calculated successfully",
+			Data:    layout,
+		})
+	}
+}
+
+// cutSheetOptions reads the sheet size, margin, bleed and rotation from the
+// query. On failure it writes a 400 response and returns false.
+func cutSheetOptions(c *gin.Context) (nesting.Options, bool) {
+	opts := nesting.Options{AllowRotation: c.Query("rotate") != "false"}
+
+	size := strings.ToLower(c.DefaultQuery("size", "a4"))
+	if size == "custom" {
+		width, ok := queryLength(c, "width_cm", 0, minSheetSize, maxSheetSize)
+		if !ok {
+			return opts, false
+		}
+		height, ok := queryLength(c, "height_cm", 0, minSheetSize, maxSheetSize)
+		if !ok {
+			return opts, false
+		}
+		opts.Sheet = nesting.Sheet{Name: "Custom", WidthCM: width, HeightCM: height}
+	} else if sheet, known := nesting.Sizes[size]; known {
+		opts.Sheet = sheet
+	} else {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid sheet size",
+			Error:   "size must be a4, a3, letter or custom",
+		})
+		return opts, false
+	}
+
+	var ok bool
+	if opts.MarginCM, ok = queryLength(c, "margin_cm", 1, 0, maxMargin); !ok {
+		return opts, false
+	}
+	if opts.BleedCM, ok = queryLength(c, "bleed_cm", 0.3, 0, maxBleed); !ok {
+		return opts, false
+	}
+	if 2*opts.MarginCM >= math.Min(opts.Sheet.WidthCM, opts.Sheet.HeightCM) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid margin_cm",
+			Error:   "The margins leave no room on the sheet",
+		})
+		return opts, false
+	}
+
+	return opts, true
+}
+
+// queryLength parses a length in cm from the query, falling back to def when
+// absent. A def of 0 makes the parameter required. On failure it writes a 400
+// response and returns false.
+func queryLength(c *gin.Context, name string, def, min, max float64) (float64, bool) {
+	value := c.Query(name)
+	if value == "" && def > 0 {
+		return def, true
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(parsed) || parsed < min || parsed > max {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid " + name,
+			Error:   fmt.Sprintf("%s must be a number between %g and %g", name, min, max),
+		})
+		return 0, false
+	}
+	return parsed, true
+}
+
+// nestLayers lays every layer of a production plan out on sheets
+func nestLayers(plan *production.Plan, opts nesting.Options) *nesting.Layout {
+	pieces := make([]nesting.Piece, len(plan.Layers))
+	for i, layer := range plan.Layers {
+		pieces[i] = nesting.Piece{
+			Order:    layer.Order,
+			File:     layer.File,
+			ObjectID: layer.ObjectID,
+			Layer:    layer.Layer,
+			WidthCM:  layer.WidthCM,
+			HeightCM: layer.HeightCM,
+		}
+	}
+	return nesting.Pack(pieces, opts)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/cutline"
+	"scrapyuk-backend/internal/imaging"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bounds of the cut line parameters; lengths are in centimetres
+const (
+	minCutlineOffset    = -1.0
+	maxCutlineOffset    = 2.0
+	maxCutlineKerf      = 0.5
+	minCutlineTolerance = 0.001
+	maxCutlineTolerance = 1.0
+	minCutlineScale     = 0.01
+	maxCutlineScale     = 100.0
+)
+
+// cutlinePath matches cut line requests on the asset file route, which gin
+// cannot register separately next to its catch-all parameter
+var cutlinePath = regexp.MustCompile(`^(\d+)/cutline\.(svg|dxf)$`)
+
+// serveCutline handles GET /api/assets/:id/cutline.svg and .dxf - trace the
+// outline of an asset's opaque pixels for a cutting plotter. ?offset_cm= and
+// ?kerf_cm= move the line outwards, ?tolerance_cm= sets how closely it follows
+// the pixels, ?threshold= is the lowest alpha counted as artwork and ?scale=
+// matches the print size of an object.
+func (h *AssetHandler) serveCutline(c *gin.Context, id, format string) {
+	if currentUserID(c) == 0 {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "Sign in to download cut lines",
+		})
+		return
+	}
+
+	opts, ok := cutlineOptions(c)
+	if !ok {
+		return
+	}
+
+	var asset models.Asset
+	if err := config.GetDB().Scopes(ownedThroughProject(c, "assets")).First(&asset, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Asset not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err := opts.Check(asset.Width, asset.Height); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid offset_cm",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	object, _, err := config.GetStorage().Get(c.Request.Context(), asset.FilePath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to read asset",
+			Error:   err.Error(),
+		})
+		return
+	}
+	data, err := io.ReadAll(object)
+	object.Close()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to read asset",
+			Error:   err.Error(),
+		})
+		return
+	}
+	img, _, err := imaging.InspectPNG(data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to decode asset",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	cut, err := cutline.Trace(img, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid offset_cm",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if len(cut.Outlines) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Nothing to cut",
+			Error:   "The asset has no pixels at or above the alpha threshold",
+		})
+		return
+	}
+
+	// The router defaults every response to JSON
+	filename := cutlineFilename(&asset, format)
+	switch format {
+	case "dxf":
+		c.Header("Content-Type", "application/dxf")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Data(http.StatusOK, "application/dxf", cut.DXF())
+	default:
+		c.Header("Content-Type", "image/svg+xml")
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+		c.Data(http.StatusOK, "image/svg+xml", cut.SVG())
+	}
+}
+
+// cutlineOptions reads the tracing parameters from the query. On failure it
+// writes a 400 response and returns false.
+func cutlineOptions(c *gin.Context) (cutline.Options, bool) {
+	opts := cutline.Options{Threshold: 128, ToleranceCM: 0.02}
+
+	scale := 1.0
+	if c.Query("scale") != "" {
+		var ok bool
+		if scale, ok = queryLength(c, "scale", 0, minCutlineScale, maxCutlineScale); !ok {
+			return opts, false
+		}
+	}
+	opts.CMPerPixel = scale * scene.CMPerInch / scene.ReferenceDPI
+
+	var offset, kerf float64
+	var ok bool
+	if c.Query("offset_cm") != "" {
+		if offset, ok = queryLength(c, "offset_cm", 0, minCutlineOffset, maxCutlineOffset); !ok {
+			return opts, false
+		}
+	}
+	if c.Query("kerf_cm") != "" {
+		if kerf, ok = queryLength(c, "kerf_cm", 0, 0, maxCutlineKerf); !ok {
+			return opts, false
+		}
+	}
+	// The blade cuts along the middle of its kerf, so the line is moved out
+	// by half of it to keep the piece full size
+	opts.OffsetCM = offset + kerf/2
+
+	if opts.ToleranceCM, ok = queryLength(c, "tolerance_cm", opts.ToleranceCM, minCutlineTolerance, maxCutlineTolerance); !ok {
+		return opts, false
+	}
+
+	if value := c.Query("threshold"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold < 1 || threshold > 255 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid threshold",
+				Error:   "threshold must be an alpha value between 1 and 255",
+			})
+			return opts, false
+		}
+		opts.Threshold = uint8(threshold)
+	}
+
+	return opts, true
+}
+
+// cutlineFilename names a cut line download after the asset's file
+func cutlineFilename(asset *models.Asset, format string) string {
+	name := strings.TrimSuffix(path.Base(asset.Filename), path.Ext(asset.Filename))
+	name = strings.Trim(unsafeFilenameChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		name = fmt.Sprintf("asset-%d", asset.ID)
+	}
+	return name + "-cutline." + format
+}
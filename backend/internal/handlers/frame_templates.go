@@ -0,0 +1,446 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FrameTemplateHandler handles frame template-related HTTP requests
+type FrameTemplateHandler struct{}
+
+// NewFrameTemplateHandler creates a new frame template handler
+func NewFrameTemplateHandler() *FrameTemplateHandler {
+	return &FrameTemplateHandler{}
+}
+
+// GetFrameTemplates handles GET /api/frame-templates - list every frame template
+func (h *FrameTemplateHandler) GetFrameTemplates(c *gin.Context) {
+	var templates []models.FrameTemplate
+	if err := config.GetDB().Order("width_cm ASC, height_cm ASC, name ASC").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch frame templates",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Frame templates fetched successfully",
+		Data:    templates,
+	})
+}
+
+// GetFrameTemplate handles GET /api/frame-templates/:id - get a single frame template
+func (h *FrameTemplateHandler) GetFrameTemplate(c *gin.Context) {
+	template, ok := findFrameTemplate(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Frame template fetched successfully",
+		Data:    template,
+	})
+}
+
+// CreateFrameTemplate handles POST /api/frame-templates - add a frame template
+func (h *FrameTemplateHandler) CreateFrameTemplate(c *gin.Context) {
+	db := config.GetDB()
+
+	var req models.FrameTemplateCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	template := models.FrameTemplate{
+		Name:         strings.TrimSpace(req.Name),
+		WidthCM:      req.WidthCM,
+		HeightCM:     req.HeightCM,
+		DepthCM:      req.DepthCM,
+		MaxLayers:    req.MaxLayers,
+		WallMaterial: strings.TrimSpace(req.WallMaterial),
+		LEDMounts:    req.LEDMounts,
+	}
+	if template.LEDMounts == nil {
+		template.LEDMounts = []models.Vector3{}
+	}
+	if !validFrameTemplate(c, db, &template) {
+		return
+	}
+
+	if err := db.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to create frame template",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Frame template created successfully",
+		Data:    template,
+	})
+}
+
+// UpdateFrameTemplate handles PUT /api/frame-templates/:id - update a frame template.
+// Changes that would leave objects of an existing project outside the frame are rejected.
+func (h *FrameTemplateHandler) UpdateFrameTemplate(c *gin.Context) {
+	db := config.GetDB()
+
+	template, ok := findFrameTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req models.FrameTemplateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Update fields if provided
+	if req.Name != nil {
+		template.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.WidthCM != nil {
+		template.WidthCM = *req.WidthCM
+	}
+	if req.HeightCM != nil {
+		template.HeightCM = *req.HeightCM
+	}
+	if req.DepthCM != nil {
+		template.DepthCM = *req.DepthCM
+	}
+	if req.MaxLayers != nil {
+		template.MaxLayers = *req.MaxLayers
+	}
+	if req.WallMaterial != nil {
+		template.WallMaterial = strings.TrimSpace(*req.WallMaterial)
+	}
+	if req.LEDMounts != nil {
+		template.LEDMounts = *req.LEDMounts
+		if template.LEDMounts == nil {
+			template.LEDMounts = []models.Vector3{}
+		}
+	}
+	if !validFrameTemplate(c, db, template) {
+		return
+	}
+
+	var projects []models.Project
+	err := db.Select("id", "owner_id", "project_data").
+		Preload("Objects", orderedObjects).
+		Where("frame_template_id = ?", template.ID).Find(&projects).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to update frame template",
+			Error:   err.Error(),
+		})
+		return
+	}
+	// Other creators' projects are only counted, never identified
+	misfits, ownMisfits := 0, []uint{}
+	for _, project := range projects {
+		doc, err := scene.Parse(project.ProjectData)
+		if err != nil {
+			// Unreadable scene data is left to the editor; the Object rows are still checked
+			doc = &scene.Document{}
+		}
+		doc.Objects = append(doc.Objects, sceneObjectsFromRows(project.Objects)...)
+		if doc.CheckBounds(template.Bounds()) != nil {
+			misfits++
+			if project.OwnerID == currentUserID(c) {
+				ownMisfits = append(ownMisfits, project.ID)
+			}
+		}
+	}
+	if misfits > 0 {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Frame template is in use",
+			Error:   fmt.Sprintf("Objects in %d existing project(s) would no longer fit the frame", misfits),
+			Details: map[string]interface{}{
+				"project_count": misfits,
+				"project_ids":   ownMisfits,
+			},
+		})
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(template).Error; err != nil {
+			return err
+		}
+		// Keep the size label of projects built for this frame in step
+		return tx.Model(&models.Project{}).
+			Where("frame_template_id = ?", template.ID).
+			Update("frame_size", template.SizeLabel()).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to update frame template",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Frame template updated successfully",
+		Data:    template,
+	})
+}
+
+// DeleteFrameTemplate handles DELETE /api/frame-templates/:id - delete a frame
+// template that no project uses
+func (h *FrameTemplateHandler) DeleteFrameTemplate(c *gin.Context) {
+	db := config.GetDB()
+
+	template, ok := findFrameTemplate(c)
+	if !ok {
+		return
+	}
+
+	// Soft-deleted projects still reference the template and may be restored
+	var inUse int64
+	db.Unscoped().Model(&models.Project{}).Where("frame_template_id = ?", template.ID).Count(&inUse)
+	if inUse > 0 {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Frame template is in use",
+			Error:   fmt.Sprintf("%d project(s) are built for this frame", inUse),
+		})
+		return
+	}
+
+	if err := db.Delete(template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to delete frame template",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Frame template deleted successfully",
+	})
+}
+
+// findFrameTemplate loads the frame template identified by the :id route
+// parameter. On failure it writes the error response and returns false.
+func findFrameTemplate(c *gin.Context) (*models.FrameTemplate, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid frame template ID",
+			Error:   "Frame template ID must be a valid number",
+		})
+		return nil, false
+	}
+
+	var template models.FrameTemplate
+	if err := config.GetDB().First(&template, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Frame template not found",
+			Error:   err.Error(),
+		})
+		return nil, false
+	}
+
+	return &template, true
+}
+
+// validFrameTemplate checks that the template's name is unique and its LED
+// mounts lie inside the frame. On failure it writes the error response.
+func validFrameTemplate(c *gin.Context, db *gorm.DB, template *models.FrameTemplate) bool {
+	if template.Name == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   "name cannot be blank",
+		})
+		return false
+	}
+
+	var fieldErrs scene.ValidationErrors
+	for i, mount := range template.LEDMounts {
+		field := "led_mounts." + strconv.Itoa(i)
+		if err := validateVector(field, &mount); err != nil {
+			fieldErrs = append(fieldErrs, scene.FieldError{Field: field, Message: "must contain finite numbers"})
+			continue
+		}
+		if !template.Contains(mount) {
+			fieldErrs = append(fieldErrs, scene.FieldError{Field: field, Message: "must lie inside the frame"})
+		}
+	}
+	if len(fieldErrs) > 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid frame template",
+			Error:   fieldErrs.Error(),
+			Details: fieldErrs,
+		})
+		return false
+	}
+
+	var existing int64
+	db.Model(&models.FrameTemplate{}).Where("name = ? AND id <> ?", template.Name, template.ID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Frame template already exists",
+			Error:   "Another frame template is named " + strconv.Quote(template.Name),
+		})
+		return false
+	}
+
+	return true
+}
+
+// resolveFrameTemplate finds the template a project request refers to, either
+// by ID or by a "<width>x<height>" frame size. On failure it writes a 400
+// response and returns false.
+func resolveFrameTemplate(c *gin.Context, id *uint, size *string) (*models.FrameTemplate, bool) {
+	db := config.GetDB()
+
+	var template *models.FrameTemplate
+	var err error
+	if id != nil {
+		template = &models.FrameTemplate{}
+		err = db.First(template, *id).Error
+	} else {
+		template, err = frameTemplateForSize(db, strings.TrimSpace(*size))
+	}
+
+	if err != nil {
+		message := "Frame template not found"
+		if errors.Is(err, gorm.ErrRecordNotFound) && id == nil {
+			message = "No frame template has size " + strconv.Quote(*size)
+		}
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid frame template",
+			Error:   message,
+		})
+		return nil, false
+	}
+
+	return template, true
+}
+
+// frameTemplateForSize returns the first template whose "<width>x<height>"
+// label is size, or gorm.ErrRecordNotFound
+func frameTemplateForSize(db *gorm.DB, size string) (*models.FrameTemplate, error) {
+	var templates []models.FrameTemplate
+	if err := db.Order("id ASC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if templates[i].SizeLabel() == size {
+			return &templates[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// projectFrameTemplate loads the template a project is built for, or nil for
+// projects created before templates existed whose size matched none
+func projectFrameTemplate(db *gorm.DB, project *models.Project) (*models.FrameTemplate, error) {
+	if project.FrameTemplateID == nil {
+		return nil, nil
+	}
+	var template models.FrameTemplate
+	if err := db.First(&template, *project.FrameTemplateID).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// sceneFitsFrame checks that every object of the scene lies inside the frame.
+// On failure it writes a 400 response listing the objects that do not fit.
+func sceneFitsFrame(c *gin.Context, data json.RawMessage, template *models.FrameTemplate) bool {
+	if template == nil {
+		return true
+	}
+
+	doc, err := scene.Parse(data)
+	if err == nil {
+		err = doc.CheckBounds(template.Bounds())
+	}
+	return respondMisfit(c, err)
+}
+
+// documentFitsFrame is sceneFitsFrame for a parsed scene, such as one merged
+// with the project's Object rows
+func documentFitsFrame(c *gin.Context, doc *scene.Document, template *models.FrameTemplate) bool {
+	if template == nil {
+		return true
+	}
+	return respondMisfit(c, doc.CheckBounds(template.Bounds()))
+}
+
+// sceneAndObjectsFitFrame is sceneFitsFrame for a scene document together with
+// the project's Object rows, for when a project moves to another frame
+func sceneAndObjectsFitFrame(c *gin.Context, data json.RawMessage, rows []models.Object, template *models.FrameTemplate) bool {
+	if template == nil {
+		return true
+	}
+
+	doc, err := scene.Parse(data)
+	if err != nil {
+		return respondMisfit(c, err)
+	}
+	doc.Objects = append(doc.Objects, sceneObjectsFromRows(rows)...)
+	return documentFitsFrame(c, doc, template)
+}
+
+// respondMisfit writes the 400 response for a failed frame check and reports
+// whether the check passed
+func respondMisfit(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	response := models.APIResponse{
+		Success: false,
+		Message: "Project data does not fit the frame",
+		Error:   err.Error(),
+	}
+	var fieldErrs scene.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		response.Details = fieldErrs
+	}
+	c.JSON(http.StatusBadRequest, response)
+	return false
+}
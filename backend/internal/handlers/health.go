@@ -5,16 +5,20 @@ import (
 
 	"scrapyuk-backend/config"
 	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scheduler"
 
 	"github.com/gin-gonic/gin"
 )
 
 // HealthHandler handles health check requests
-type HealthHandler struct{}
+type HealthHandler struct {
+	jobs *scheduler.Scheduler
+}
 
-// NewHealthHandler creates a new health handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+// NewHealthHandler creates a new health handler. jobs may be nil when
+// background jobs are disabled.
+func NewHealthHandler(jobs *scheduler.Scheduler) *HealthHandler {
+	return &HealthHandler{jobs: jobs}
 }
 
 // HealthCheck handles GET /health - basic health check
@@ -48,20 +52,36 @@ func (h *HealthHandler) DetailedHealthCheck(c *gin.Context) {
 		httpStatus = http.StatusServiceUnavailable
 	}
 
-	// Check MinIO health
-	if !config.IsMinIOAvailable() {
+	// Check storage health
+	if !config.IsStorageAvailable() {
 		healthStatus["storage"] = "unavailable"
-		healthStatus["storage_error"] = "MinIO not configured or unreachable"
+		healthStatus["storage_error"] = "Storage backend not configured or unreachable"
 		if healthStatus["status"] == "ok" {
 			healthStatus["status"] = "degraded"
 		}
-		// Don't change HTTP status for MinIO being unavailable as it's optional
-	} else if err := config.HealthCheckMinIO(); err != nil {
+		// Don't change HTTP status for storage being unavailable as it's optional
+	} else if err := config.HealthCheckStorage(); err != nil {
+		healthStatus["storage_driver"] = config.GetStorage().Name()
 		healthStatus["storage"] = "error"
 		healthStatus["storage_error"] = err.Error()
 		if healthStatus["status"] == "ok" {
 			healthStatus["status"] = "degraded"
 		}
+	} else {
+		healthStatus["storage_driver"] = config.GetStorage().Name()
+	}
+
+	// Report background job status; a failing job degrades but does not fail the check
+	if h.jobs != nil {
+		statuses := h.jobs.Status()
+		healthStatus["jobs"] = statuses
+		for _, status := range statuses {
+			if status.LastError != "" && healthStatus["status"] == "ok" {
+				healthStatus["status"] = "degraded"
+			}
+		}
+	} else {
+		healthStatus["jobs"] = "disabled"
 	}
 
 	c.JSON(httpStatus, models.APIResponse{
@@ -0,0 +1,606 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ObjectHandler handles scene object-related HTTP requests
+type ObjectHandler struct{}
+
+// NewObjectHandler creates a new object handler
+func NewObjectHandler() *ObjectHandler {
+	return &ObjectHandler{}
+}
+
+// GetProjectObjects handles GET /api/projects/:id/objects - list scene objects in render order
+func (h *ObjectHandler) GetProjectObjects(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	var objects []models.Object
+	if err := db.Where("project_id = ?", project.ID).Order("sort_order ASC, id ASC").Find(&objects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch objects",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Objects fetched successfully",
+		Data:    objects,
+	})
+}
+
+// CreateObject handles POST /api/projects/:id/objects - add a scene object to a project
+func (h *ObjectHandler) CreateObject(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	var req models.ObjectCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := validateObjectFields(db, project, req.AssetID, req.Position, req.Properties); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid object data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	object := models.Object{
+		ProjectID:  project.ID,
+		AssetID:    req.AssetID,
+		Layers:     1,
+		Properties: req.Properties,
+	}
+	object.Position, _ = json.Marshal(req.Position)
+	if req.Layers != nil {
+		object.Layers = *req.Layers
+	}
+	if !objectFits(c, db, project, &object) {
+		return
+	}
+
+	// New objects go on top unless an explicit order is given
+	if req.SortOrder != nil {
+		object.SortOrder = *req.SortOrder
+	} else {
+		var maxOrder *int
+		db.Model(&models.Object{}).Where("project_id = ?", project.ID).Select("MAX(sort_order)").Scan(&maxOrder)
+		if maxOrder != nil {
+			object.SortOrder = *maxOrder + 1
+		}
+	}
+
+	err := commitObjectChange(c, project, func(tx *gorm.DB) error {
+		return tx.Create(&object).Error
+	})
+	if errors.Is(err, errVersionConflict) {
+		respondVersionConflict(c, project.ID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to create object",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Object created successfully",
+		Data:    object,
+	})
+}
+
+// UpdateObject handles PUT /api/projects/:id/objects/:objectId - update a single scene object
+func (h *ObjectHandler) UpdateObject(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	objectID, err := strconv.ParseUint(c.Param("objectId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid object ID",
+			Error:   "Object ID must be a valid number",
+		})
+		return
+	}
+
+	var req models.ObjectUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var object models.Object
+	if err := db.Where("project_id = ?", project.ID).First(&object, objectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Object not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := validateObjectFields(db, project, req.AssetID, req.Position, req.Properties); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid object data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	applyObjectUpdate(&object, &req)
+	if !objectFits(c, db, project, &object) {
+		return
+	}
+
+	err = commitObjectChange(c, project, func(tx *gorm.DB) error {
+		return tx.Save(&object).Error
+	})
+	if errors.Is(err, errVersionConflict) {
+		respondVersionConflict(c, project.ID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to update object",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Object updated successfully",
+		Data:    object,
+	})
+}
+
+// BatchUpdateObjects handles PATCH /api/projects/:id/objects - update several scene objects atomically
+func (h *ObjectHandler) BatchUpdateObjects(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	var req models.ObjectBatchUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	template, err := projectFrameTemplate(db, project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load frame template",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Each object is checked as it will be saved, with every earlier update to
+	// it in this batch applied. Unknown IDs are reported by the update below.
+	pending := make(map[uint]*models.Object, len(req.Objects))
+	for i, item := range req.Objects {
+		if err := validateObjectFields(db, project, item.AssetID, item.Position, item.Properties); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid object data",
+				Error:   fmt.Sprintf("objects[%d]: %s", i, err.Error()),
+			})
+			return
+		}
+
+		object, ok := pending[item.ID]
+		if !ok {
+			object = &models.Object{}
+			if err := db.Where("project_id = ?", project.ID).First(object, item.ID).Error; err != nil {
+				continue
+			}
+			pending[item.ID] = object
+		}
+		applyObjectUpdate(object, &item.ObjectUpdateRequest)
+		if !respondObjectMisfit(c, fmt.Sprintf("objects[%d]: ", i), objectFitsFrame(template, object)) {
+			return
+		}
+	}
+
+	var updated []models.Object
+	err = commitObjectChange(c, project, func(tx *gorm.DB) error {
+		for _, item := range req.Objects {
+			var object models.Object
+			if err := tx.Where("project_id = ?", project.ID).First(&object, item.ID).Error; err != nil {
+				return fmt.Errorf("object %d: %w", item.ID, err)
+			}
+
+			applyObjectUpdate(&object, &item.ObjectUpdateRequest)
+
+			if err := tx.Save(&object).Error; err != nil {
+				return err
+			}
+			updated = append(updated, object)
+		}
+		return nil
+	})
+	if errors.Is(err, errVersionConflict) {
+		respondVersionConflict(c, project.ID)
+		return
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "Failed to update objects"
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+			message = "Object not found"
+		}
+		c.JSON(status, models.APIResponse{
+			Success: false,
+			Message: message,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Objects updated successfully",
+		Data:    updated,
+	})
+}
+
+// ReorderObjects handles POST /api/projects/:id/objects/reorder - set the render order of all objects
+func (h *ObjectHandler) ReorderObjects(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	var req models.ObjectReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var objects []models.Object
+	if err := db.Where("project_id = ?", project.ID).Find(&objects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch objects",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// The new order must list every object in the project exactly once
+	existing := make(map[uint]bool, len(objects))
+	for _, object := range objects {
+		existing[object.ID] = true
+	}
+	seen := make(map[uint]bool, len(req.ObjectIDs))
+	for _, id := range req.ObjectIDs {
+		if !existing[id] || seen[id] {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid object order",
+				Error:   fmt.Sprintf("object %d is unknown or listed more than once", id),
+			})
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(existing) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid object order",
+			Error:   "object_ids must include every object in the project",
+		})
+		return
+	}
+
+	err := commitObjectChange(c, project, func(tx *gorm.DB) error {
+		for order, id := range req.ObjectIDs {
+			if err := tx.Model(&models.Object{}).Where("id = ?", id).Update("sort_order", order).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errVersionConflict) {
+		respondVersionConflict(c, project.ID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to reorder objects",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	db.Where("project_id = ?", project.ID).Order("sort_order ASC, id ASC").Find(&objects)
+
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Objects reordered successfully",
+		Data:    objects,
+	})
+}
+
+// DeleteObject handles DELETE /api/projects/:id/objects/:objectId - remove a scene object
+func (h *ObjectHandler) DeleteObject(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	objectID, err := strconv.ParseUint(c.Param("objectId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid object ID",
+			Error:   "Object ID must be a valid number",
+		})
+		return
+	}
+
+	var object models.Object
+	if err := db.Where("project_id = ?", project.ID).First(&object, objectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Object not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	err = commitObjectChange(c, project, func(tx *gorm.DB) error {
+		return tx.Delete(&object).Error
+	})
+	if errors.Is(err, errVersionConflict) {
+		respondVersionConflict(c, project.ID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to delete object",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Object deleted successfully",
+	})
+}
+
+// commitObjectChange applies change to the project's Object rows and saves the
+// project as a new version with a matching revision, in one transaction. It
+// fails with errVersionConflict if the request's If-Match is stale or another
+// request saved the project first.
+func commitObjectChange(c *gin.Context, project *models.Project, change func(tx *gorm.DB) error) error {
+	if !ifMatchSatisfied(c, project) {
+		return errVersionConflict
+	}
+	return config.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := change(tx); err != nil {
+			return err
+		}
+		return commitProjectChange(tx, project, currentUserID(c), "objects", nil)
+	})
+}
+
+// applyObjectUpdate copies the provided fields of req onto object
+func applyObjectUpdate(object *models.Object, req *models.ObjectUpdateRequest) {
+	if req.AssetID != nil {
+		object.AssetID = req.AssetID
+	}
+	if req.Position != nil {
+		object.Position, _ = json.Marshal(req.Position)
+	}
+	if req.Layers != nil {
+		object.Layers = *req.Layers
+	}
+	if req.Properties != nil {
+		object.Properties = req.Properties
+	}
+	if req.SortOrder != nil {
+		object.SortOrder = *req.SortOrder
+	}
+}
+
+// validateObjectFields checks that the referenced asset belongs to the same
+// project, the position is finite and the properties are a well-formed object.
+// Whether the resulting object fits the frame is checked by objectFitsFrame.
+func validateObjectFields(db *gorm.DB, project *models.Project, assetID *uint, position *models.Vector3, properties json.RawMessage) error {
+	if assetID != nil {
+		var count int64
+		db.Model(&models.Asset{}).Where("id = ? AND project_id = ?", *assetID, project.ID).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("asset %d does not exist in this project", *assetID)
+		}
+	}
+
+	if position != nil {
+		if err := validateVector("position", position); err != nil {
+			return err
+		}
+	}
+
+	if properties != nil {
+		if err := validateObjectProperties(properties); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// objectFits checks the object against the project's frame and writes the
+// error response when it does not fit
+func objectFits(c *gin.Context, db *gorm.DB, project *models.Project, object *models.Object) bool {
+	template, err := projectFrameTemplate(db, project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load frame template",
+			Error:   err.Error(),
+		})
+		return false
+	}
+	return respondObjectMisfit(c, "", objectFitsFrame(template, object))
+}
+
+// respondObjectMisfit writes the 400 response for an object that does not fit
+// the frame, prefixing the error with where the object was given, and reports
+// whether it fits
+func respondObjectMisfit(c *gin.Context, prefix string, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	response := models.APIResponse{
+		Success: false,
+		Message: "Invalid object data",
+		Error:   prefix + err.Error(),
+	}
+	var fieldErrs scene.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		response.Details = fieldErrs
+	}
+	c.JSON(http.StatusBadRequest, response)
+	return false
+}
+
+// objectFitsFrame checks an object's position and layer stack against the
+// frame with the same rules as the objects of the scene document, so a saved
+// row never keeps its frame from being edited
+func objectFitsFrame(template *models.FrameTemplate, object *models.Object) error {
+	if template == nil {
+		return nil
+	}
+
+	doc := scene.Document{Objects: []scene.Object{sceneObjectFromRow(object)}}
+	err := doc.CheckBounds(template.Bounds())
+	var fieldErrs scene.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		// Name the fields of the object rather than of the one-object document
+		for i := range fieldErrs {
+			fieldErrs[i].Field = strings.TrimPrefix(fieldErrs[i].Field, "objects.0.")
+		}
+	}
+	return err
+}
+
+// objectProperties lists the well-known object properties used by the editor
+type objectProperties struct {
+	Scale        *float64        `json:"scale"`
+	LayerSpacing *float64        `json:"layerSpacing"`
+	Rotation     *models.Vector3 `json:"rotation"`
+}
+
+// validateObjectProperties requires a JSON object and checks the types and
+// ranges of the well-known keys; other keys are kept as-is
+func validateObjectProperties(raw json.RawMessage) error {
+	var generic map[string]interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil || generic == nil {
+		return errors.New("properties must be a JSON object")
+	}
+
+	var props objectProperties
+	if err := json.Unmarshal(raw, &props); err != nil {
+		return fmt.Errorf("properties: %w", err)
+	}
+
+	if props.Scale != nil && (*props.Scale <= 0 || math.IsInf(*props.Scale, 0)) {
+		return errors.New("properties.scale must be greater than 0")
+	}
+	if props.LayerSpacing != nil && (*props.LayerSpacing < 0 || math.IsInf(*props.LayerSpacing, 0)) {
+		return errors.New("properties.layerSpacing must not be negative")
+	}
+	if props.Rotation != nil {
+		if err := validateVector("properties.rotation", props.Rotation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateVector(field string, v *models.Vector3) error {
+	for _, value := range []float64{v.X, v.Y, v.Z} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("%s must contain finite numbers", field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/middleware"
+	"scrapyuk-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// currentUserID returns the ID of the authenticated creator, or 0 if none
+func currentUserID(c *gin.Context) uint {
+	if user := middleware.CurrentUser(c); user != nil {
+		return user.ID
+	}
+	return 0
+}
+
+// ownedProjects scopes project queries to the authenticated creator
+func ownedProjects(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	userID := currentUserID(c)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("projects.owner_id = ?", userID)
+	}
+}
+
+// ownedThroughProject scopes queries on a project-owned table (assets, objects,
+// shared_links) to rows whose project belongs to the authenticated creator
+func ownedThroughProject(c *gin.Context, table string) func(*gorm.DB) *gorm.DB {
+	userID := currentUserID(c)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("JOIN projects ON projects.id = "+table+".project_id AND projects.deleted_at IS NULL").
+			Where("projects.owner_id = ?", userID)
+	}
+}
+
+// findOwnedProject loads the project identified by the :id route parameter if it
+// belongs to the authenticated creator. On failure it writes the error response
+// and returns false.
+func findOwnedProject(c *gin.Context) (*models.Project, bool) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid project ID",
+			Error:   "Project ID must be a valid number",
+		})
+		return nil, false
+	}
+
+	var project models.Project
+	if err := config.GetDB().Scopes(ownedProjects(c)).First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Project not found",
+			Error:   err.Error(),
+		})
+		return nil, false
+	}
+
+	return &project, true
+}
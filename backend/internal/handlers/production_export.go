@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/imaging"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/production"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportProduction handles GET /api/projects/:id/production-export - stream a ZIP
+// with one print-ready PNG per paper layer, sized for the project's frame at the
+// requested ?dpi=, and a cutting sheet in JSON and CSV
+func (h *ProjectHandler) ExportProduction(c *gin.Context) {
+	db := config.GetDB()
+
+	dpi := production.DefaultDPI
+	if value := c.Query("dpi"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < production.MinDPI || parsed > production.MaxDPI {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid dpi",
+				Error:   fmt.Sprintf("dpi must be between %d and %d", production.MinDPI, production.MaxDPI),
+			})
+			return
+		}
+		dpi = parsed
+	}
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+	if err := db.Preload("FrameTemplate").Preload("Assets").Preload("Objects", orderedObjects).First(project, project.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	plan, ok := buildProductionPlan(c, project, dpi)
+	if !ok {
+		return
+	}
+	if err := plan.CheckPrintSize(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Layers too large to render",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	assets := make(map[uint]*models.Asset, len(project.Assets))
+	for i := range project.Assets {
+		assets[project.Assets[i].ID] = &project.Assets[i]
+	}
+
+	if len(plan.Layers) > 0 && !config.IsStorageAvailable() {
+		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Message: "File storage service unavailable",
+		})
+		return
+	}
+
+	// Check every file up front, since errors cannot be reported once the
+	// archive has started streaming
+	ctx := c.Request.Context()
+	for _, layer := range plan.Layers {
+		if layer.Layer != 1 {
+			continue
+		}
+		if _, err := config.GetStorage().Stat(ctx, assets[layer.AssetID].FilePath); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to export project",
+				Error:   fmt.Sprintf("asset %d: %v", layer.AssetID, err),
+			})
+			return
+		}
+	}
+
+	name := downloadName(project)
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%d-production.zip"`, name, project.ID))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	if err := writeProductionArchive(ctx, c.Writer, plan, assets); err != nil {
+		// The status line has been sent, so the truncated archive is all the
+		// client will see
+		log.Printf("Failed to export production files for project %d: %v", project.ID, err)
+		c.Abort()
+	}
+}
+
+// maxConcurrentRenders bounds how many layer prints are rendered at once
+// across all exports, as each one may hold a few hundred megabytes
+const maxConcurrentRenders = 2
+
+var renderSlots = make(chan struct{}, maxConcurrentRenders)
+
+// errNoFrameTemplate is returned for projects that are not built for a frame template
+var errNoFrameTemplate = errors.New("project has no frame template")
+
+// buildProductionPlan lays out the layers of a project loaded with its frame
+// template, assets and objects. On failure it writes the error response and returns false.
+func buildProductionPlan(c *gin.Context, project *models.Project, dpi int) (*production.Plan, bool) {
+	plan, err := productionPlan(project, dpi)
+	if errors.Is(err, errNoFrameTemplate) {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Project has no frame template",
+			Error:   "Choose a frame template so the layers can be sized for it",
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Invalid project data",
+			Error:   err.Error(),
+		})
+		return nil, false
+	}
+
+	return plan, true
+}
+
+// productionPlan lays out the layers of a project loaded with its frame
+// template, assets and objects
+func productionPlan(project *models.Project, dpi int) (*production.Plan, error) {
+	template := project.FrameTemplate
+	if template == nil {
+		return nil, errNoFrameTemplate
+	}
+
+	doc, err := scene.Parse(project.ProjectData)
+	if err != nil {
+		return nil, err
+	}
+	doc.Objects = append(doc.Objects, sceneObjectsFromRows(project.Objects)...)
+
+	assets := make(map[scene.Ref]production.Asset, len(project.Assets))
+	for _, asset := range project.Assets {
+		assets[scene.Ref(strconv.FormatUint(uint64(asset.ID), 10))] = production.Asset{
+			ID:     asset.ID,
+			Width:  asset.Width,
+			Height: asset.Height,
+		}
+	}
+
+	frame := production.Frame{
+		Name:     template.Name,
+		WidthCM:  template.WidthCM,
+		HeightCM: template.HeightCM,
+		DepthCM:  template.DepthCM,
+	}
+	return production.Build(doc, frame, assets, dpi), nil
+}
+
+// writeProductionArchive writes the cutting sheet and every layer's print as a
+// ZIP to w. Each object is rendered once and written for each of its layers.
+func writeProductionArchive(ctx context.Context, w io.Writer, plan *production.Plan, assets map[uint]*models.Asset) error {
+	archive := zip.NewWriter(w)
+	now := time.Now()
+
+	sheetJSON, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: "cutting-sheet.json", Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(sheetJSON); err != nil {
+		return err
+	}
+
+	entry, err = archive.CreateHeader(&zip.FileHeader{Name: "cutting-sheet.csv", Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return err
+	}
+	if err := csv.NewWriter(entry).WriteAll(plan.CSV()); err != nil {
+		return err
+	}
+
+	layersByObject := make(map[int][]production.Layer)
+	var objectOrder []int
+	for _, layer := range plan.Layers {
+		if _, seen := layersByObject[layer.ObjectIndex]; !seen {
+			objectOrder = append(objectOrder, layer.ObjectIndex)
+		}
+		layersByObject[layer.ObjectIndex] = append(layersByObject[layer.ObjectIndex], layer)
+	}
+
+	for _, index := range objectOrder {
+		layers := layersByObject[index]
+		rendered, err := renderLayer(ctx, assets[layers[0].AssetID], layers[0], plan.DPI)
+		if err != nil {
+			return fmt.Errorf("object %s: %w", layers[0].ObjectID, err)
+		}
+
+		for _, layer := range layers {
+			// PNG data is already compressed
+			entry, err := archive.CreateHeader(&zip.FileHeader{Name: layer.File, Method: zip.Store, Modified: now})
+			if err != nil {
+				return err
+			}
+			if _, err := entry.Write(rendered); err != nil {
+				return err
+			}
+		}
+	}
+
+	return archive.Close()
+}
+
+// renderLayer scales an asset's original to the layer's print size, waiting
+// for a free render slot first
+func renderLayer(ctx context.Context, asset *models.Asset, layer production.Layer, dpi int) ([]byte, error) {
+	select {
+	case renderSlots <- struct{}{}:
+		defer func() { <-renderSlots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	object, _, err := config.GetStorage().Get(ctx, asset.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("asset %d: %w", asset.ID, err)
+	}
+	data, err := io.ReadAll(object)
+	object.Close()
+	if err != nil {
+		return nil, fmt.Errorf("asset %d: %w", asset.ID, err)
+	}
+
+	img, _, err := imaging.InspectPNG(data)
+	if err != nil {
+		return nil, fmt.Errorf("asset %d: %w", asset.ID, err)
+	}
+
+	return imaging.EncodePrintPNG(imaging.Scale(img, layer.WidthPx, layer.HeightPx), dpi)
+}
@@ -0,0 +1,545 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/imaging"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// archiveFormat identifies project archives in their manifest
+	archiveFormat = "scrapyuk-project"
+	// archiveFormatVersion is the manifest layout written by this server
+	archiveFormatVersion = 1
+	// archiveManifestName is the path of the manifest inside the archive
+	archiveManifestName = "manifest.json"
+	// maxArchiveSize is the largest accepted import upload in bytes
+	maxArchiveSize = 500 * 1024 * 1024
+	// maxManifestSize bounds the decompressed size of the manifest
+	maxManifestSize = 10 * 1024 * 1024
+	// maxArchiveAssets and maxArchiveObjects bound the work an import does
+	// before anything is stored
+	maxArchiveAssets  = 200
+	maxArchiveObjects = 1000
+)
+
+// archiveManifest describes a project archive. Asset and object IDs are those
+// of the exporting instance and only serve to link entries within the archive.
+type archiveManifest struct {
+	Format        string          `json:"format"`
+	FormatVersion int             `json:"format_version"`
+	SchemaVersion string          `json:"schema_version"`
+	ExportedAt    time.Time       `json:"exported_at"`
+	Project       archiveProject  `json:"project"`
+	Frame         *archiveFrame   `json:"frame,omitempty"`
+	Lighting      *scene.Lighting `json:"lighting,omitempty"`
+	Objects       []archiveObject `json:"objects"`
+	Assets        []archiveAsset  `json:"assets"`
+}
+
+type archiveProject struct {
+	Title       string          `json:"title"`
+	FrameSize   string          `json:"frame_size"`
+	IsTemplate  bool            `json:"is_template"`
+	ProjectData json.RawMessage `json:"project_data"`
+}
+
+type archiveFrame struct {
+	Name         string           `json:"name"`
+	WidthCM      float64          `json:"width_cm"`
+	HeightCM     float64          `json:"height_cm"`
+	DepthCM      float64          `json:"depth_cm"`
+	MaxLayers    int              `json:"max_layers"`
+	WallMaterial string           `json:"wall_material,omitempty"`
+	LEDMounts    []models.Vector3 `json:"led_mounts"`
+}
+
+type archiveObject struct {
+	ID         uint            `json:"id"`
+	AssetID    *uint           `json:"asset_id,omitempty"`
+	Position   json.RawMessage `json:"position"`
+	Layers     int             `json:"layers"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+	SortOrder  int             `json:"sort_order"`
+}
+
+type archiveAsset struct {
+	ID         uint      `json:"id"`
+	Filename   string    `json:"filename"`
+	File       string    `json:"file"`
+	Size       int64     `json:"size"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	HasAlpha   bool      `json:"has_alpha"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// unsafeFilenameChars matches characters replaced in download file names
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// downloadName returns the project's title made safe for download file names
+func downloadName(project *models.Project) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(project.Title, "-"), "-")
+	if name == "" {
+		name = "project"
+	}
+	return name
+}
+
+// ExportProject handles GET /api/projects/:id/export - stream a ZIP archive with
+// a manifest and the original PNG of every asset
+func (h *ProjectHandler) ExportProject(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+	if err := db.Preload("FrameTemplate").Preload("Assets").
+		Preload("Objects", func(tx *gorm.DB) *gorm.DB { return tx.Order("sort_order ASC, id ASC") }).
+		First(project, project.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if len(project.Assets) > 0 && !config.IsStorageAvailable() {
+		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Message: "File storage service unavailable",
+		})
+		return
+	}
+
+	// Check every file up front, since errors cannot be reported once the
+	// archive has started streaming
+	ctx := c.Request.Context()
+	for _, asset := range project.Assets {
+		if _, err := config.GetStorage().Stat(ctx, asset.FilePath); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to export project",
+				Error:   fmt.Sprintf("asset %d: %v", asset.ID, err),
+			})
+			return
+		}
+	}
+
+	manifest := buildArchiveManifest(project)
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to export project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	name := downloadName(project)
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%d.zip"`, name, project.ID))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	if err := writeProjectArchive(ctx, c.Writer, manifestJSON, manifest.Assets, project.Assets); err != nil {
+		// The status line has been sent, so the truncated archive is all the
+		// client will see
+		log.Printf("Failed to export project %d: %v", project.ID, err)
+		c.Abort()
+	}
+}
+
+// buildArchiveManifest describes a project with its frame, objects and assets loaded
+func buildArchiveManifest(project *models.Project) archiveManifest {
+	manifest := archiveManifest{
+		Format:        archiveFormat,
+		FormatVersion: archiveFormatVersion,
+		SchemaVersion: scene.CurrentVersion,
+		ExportedAt:    time.Now().UTC(),
+		Project: archiveProject{
+			Title:       project.Title,
+			FrameSize:   project.FrameSize,
+			IsTemplate:  project.IsTemplate,
+			ProjectData: project.ProjectData,
+		},
+		Objects: []archiveObject{},
+		Assets:  []archiveAsset{},
+	}
+
+	if frame := project.FrameTemplate; frame != nil {
+		manifest.Frame = &archiveFrame{
+			Name:         frame.Name,
+			WidthCM:      frame.WidthCM,
+			HeightCM:     frame.HeightCM,
+			DepthCM:      frame.DepthCM,
+			MaxLayers:    frame.MaxLayers,
+			WallMaterial: frame.WallMaterial,
+			LEDMounts:    frame.LEDMounts,
+		}
+	}
+	if doc, err := scene.Parse(project.ProjectData); err == nil {
+		manifest.Lighting = &doc.Settings.Lighting
+	}
+
+	for _, object := range project.Objects {
+		manifest.Objects = append(manifest.Objects, archiveObject{
+			ID:         object.ID,
+			AssetID:    object.AssetID,
+			Position:   object.Position,
+			Layers:     object.Layers,
+			Properties: object.Properties,
+			SortOrder:  object.SortOrder,
+		})
+	}
+	for _, asset := range project.Assets {
+		manifest.Assets = append(manifest.Assets, archiveAsset{
+			ID:         asset.ID,
+			Filename:   asset.Filename,
+			File:       fmt.Sprintf("assets/%d.png", asset.ID),
+			Size:       asset.Size,
+			Width:      asset.Width,
+			Height:     asset.Height,
+			HasAlpha:   asset.HasAlpha,
+			UploadedAt: asset.UploadedAt,
+		})
+	}
+
+	return manifest
+}
+
+// writeProjectArchive writes the manifest and asset originals as a ZIP to w.
+// entries and assets are parallel slices.
+func writeProjectArchive(ctx context.Context, w io.Writer, manifestJSON []byte, entries []archiveAsset, assets []models.Asset) error {
+	archive := zip.NewWriter(w)
+
+	manifestWriter, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     archiveManifestName,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := manifestWriter.Write(manifestJSON); err != nil {
+		return err
+	}
+
+	for i, asset := range assets {
+		object, _, err := config.GetStorage().Get(ctx, asset.FilePath)
+		if err != nil {
+			return fmt.Errorf("asset %d: %w", asset.ID, err)
+		}
+
+		// PNG data is already compressed
+		entryWriter, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     entries[i].File,
+			Method:   zip.Store,
+			Modified: asset.UploadedAt,
+		})
+		if err == nil {
+			_, err = io.Copy(entryWriter, object)
+		}
+		object.Close()
+		if err != nil {
+			return fmt.Errorf("asset %d: %w", asset.ID, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// ImportProject handles POST /api/projects/import - recreate a project from an
+// archive produced by ExportProject. The multipart form takes the archive as
+// "file" and optionally "title" and "frame_template_id" to override the
+// archive's title and frame.
+func (h *ProjectHandler) ImportProject(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveSize)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, models.APIResponse{
+				Success: false,
+				Message: "Archive too large",
+				Error:   fmt.Sprintf("Archives must be smaller than %d MB", maxArchiveSize/(1024*1024)),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "No file uploaded",
+			Error:   err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	archive, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		respondInvalidArchive(c, "file is not a ZIP archive")
+		return
+	}
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, entry := range archive.File {
+		entries[entry.Name] = entry
+	}
+
+	manifestJSON, err := readArchiveEntry(entries, archiveManifestName, maxManifestSize)
+	if err != nil {
+		respondInvalidArchive(c, err.Error())
+		return
+	}
+	var manifest archiveManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		respondInvalidArchive(c, "manifest.json is not valid: "+err.Error())
+		return
+	}
+	if manifest.Format != archiveFormat {
+		respondInvalidArchive(c, "manifest.json does not describe a project archive")
+		return
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > archiveFormatVersion {
+		respondInvalidArchive(c, fmt.Sprintf("unsupported archive format version %d", manifest.FormatVersion))
+		return
+	}
+	if len(manifest.Assets) > maxArchiveAssets {
+		respondInvalidArchive(c, fmt.Sprintf("archives may hold at most %d assets", maxArchiveAssets))
+		return
+	}
+	if len(manifest.Objects) > maxArchiveObjects {
+		respondInvalidArchive(c, fmt.Sprintf("archives may hold at most %d objects", maxArchiveObjects))
+		return
+	}
+
+	// Older scene versions are upgraded like any other write
+	projectData := scene.Default()
+	if len(manifest.Project.ProjectData) > 0 && string(manifest.Project.ProjectData) != "null" {
+		normalized, ok := normalizeProjectData(c, manifest.Project.ProjectData)
+		if !ok {
+			return
+		}
+		projectData = normalized
+	}
+
+	template, ok := resolveImportedFrame(c, &manifest)
+	if !ok {
+		return
+	}
+	if !sceneFitsFrame(c, projectData, template) {
+		return
+	}
+
+	source := models.Project{
+		Title:           strings.TrimSpace(manifest.Project.Title),
+		FrameTemplateID: &template.ID,
+		FrameSize:       template.SizeLabel(),
+		ProjectData:     projectData,
+		IsTemplate:      manifest.Project.IsTemplate,
+	}
+	if title := strings.TrimSpace(c.PostForm("title")); title != "" {
+		source.Title = title
+	}
+	if source.Title == "" {
+		source.Title = "Imported project"
+	}
+
+	// Validate every asset before anything is stored. Each asset has its own
+	// ID and file, so no file is read and stored more than once.
+	files := make(map[uint]string, len(manifest.Assets))
+	fileUsed := make(map[string]bool, len(manifest.Assets))
+	for _, entry := range manifest.Assets {
+		if _, ok := files[entry.ID]; ok {
+			respondInvalidArchive(c, fmt.Sprintf("asset %d is listed more than once", entry.ID))
+			return
+		}
+		if fileUsed[entry.File] {
+			respondInvalidArchive(c, fmt.Sprintf("asset %d: %s belongs to another asset", entry.ID, entry.File))
+			return
+		}
+		fileUsed[entry.File] = true
+
+		data, err := readArchiveEntry(entries, entry.File, maxAssetSize)
+		if err == nil {
+			_, _, err = imaging.InspectPNG(data)
+		}
+		if err != nil {
+			respondInvalidArchive(c, fmt.Sprintf("asset %d: %v", entry.ID, err))
+			return
+		}
+		files[entry.ID] = entry.File
+		source.Assets = append(source.Assets, models.Asset{
+			ID:         entry.ID,
+			Filename:   entry.Filename,
+			UploadedAt: entry.UploadedAt,
+		})
+	}
+
+	for i, entry := range manifest.Objects {
+		field := "objects." + strconv.Itoa(i)
+		var position models.Vector3
+		if err := json.Unmarshal(entry.Position, &position); err != nil {
+			respondInvalidArchive(c, field+".position must be a vector")
+			return
+		}
+		if err := validateObjectFields(config.GetDB(), &models.Project{FrameTemplateID: &template.ID}, nil, &position, entry.Properties); err != nil {
+			respondInvalidArchive(c, field+": "+err.Error())
+			return
+		}
+		if entry.AssetID != nil {
+			if _, ok := files[*entry.AssetID]; !ok {
+				respondInvalidArchive(c, fmt.Sprintf("%s.asset_id %d is not in the archive", field, *entry.AssetID))
+				return
+			}
+		}
+		// Layers follow the object API's rules; archives without them get one
+		layers := entry.Layers
+		if layers == 0 {
+			layers = 1
+		}
+		if layers < 1 || layers > scene.MaxLayers {
+			respondInvalidArchive(c, fmt.Sprintf("%s.layers must be between 1 and %d", field, scene.MaxLayers))
+			return
+		}
+		object := models.Object{
+			AssetID:    entry.AssetID,
+			Position:   entry.Position,
+			Layers:     layers,
+			Properties: entry.Properties,
+			SortOrder:  entry.SortOrder,
+		}
+		if err := objectFitsFrame(template, &object); err != nil {
+			respondInvalidArchive(c, field+": "+err.Error())
+			return
+		}
+		source.Objects = append(source.Objects, object)
+	}
+
+	// Assets are re-uploaded through the same path as UploadAsset, so
+	// renditions are regenerated by this instance
+	storeFromArchive := func(ctx context.Context, projectID uint, asset *models.Asset) (models.Asset, []string, error) {
+		data, err := readArchiveEntry(entries, files[asset.ID], maxAssetSize)
+		if err != nil {
+			return models.Asset{}, nil, err
+		}
+		img, info, err := imaging.InspectPNG(data)
+		if err != nil {
+			return models.Asset{}, nil, err
+		}
+		stored, keys, err := storeAssetFiles(ctx, projectID, asset.Filename, data, img, info)
+		if err != nil {
+			return models.Asset{}, nil, err
+		}
+		stored.UploadedAt = asset.UploadedAt
+		return stored, keys, nil
+	}
+
+	project, ok := copyProject(c, &source, source.Title, source.IsTemplate, storeFromArchive)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Project imported successfully",
+		Data:    project,
+	})
+}
+
+// resolveImportedFrame picks the frame template for an imported project: the
+// frame_template_id form field if given, otherwise a template with the
+// archive frame's name and dimensions, otherwise the first template with its
+// dimensions. On failure it writes the error response and returns false.
+func resolveImportedFrame(c *gin.Context, manifest *archiveManifest) (*models.FrameTemplate, bool) {
+	db := config.GetDB()
+
+	if value := c.PostForm("frame_template_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid frame template ID",
+				Error:   "Frame template ID must be a valid number",
+			})
+			return nil, false
+		}
+		templateID := uint(id)
+		return resolveFrameTemplate(c, &templateID, nil)
+	}
+
+	size := manifest.Project.FrameSize
+	if frame := manifest.Frame; frame != nil {
+		var named models.FrameTemplate
+		err := db.Where("name = ?", frame.Name).First(&named).Error
+		if err == nil && named.WidthCM == frame.WidthCM && named.HeightCM == frame.HeightCM && named.DepthCM == frame.DepthCM {
+			return &named, true
+		}
+		size = (&models.FrameTemplate{WidthCM: frame.WidthCM, HeightCM: frame.HeightCM}).SizeLabel()
+	}
+
+	template, err := frameTemplateForSize(db, size)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "No matching frame template",
+			Error:   "No frame template has size " + strconv.Quote(size) + "; create one or pass frame_template_id",
+		})
+		return nil, false
+	}
+	return template, true
+}
+
+// readArchiveEntry returns the content of the named archive entry, failing if
+// it is missing or decompresses to more than limit bytes
+func readArchiveEntry(entries map[string]*zip.File, name string, limit int64) ([]byte, error) {
+	entry, ok := entries[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is missing from the archive", name)
+	}
+
+	r, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes", name, limit)
+	}
+	return data, nil
+}
+
+// respondInvalidArchive writes a 400 response for an archive that cannot be imported
+func respondInvalidArchive(c *gin.Context, reason string) {
+	c.JSON(http.StatusBadRequest, models.APIResponse{
+		Success: false,
+		Message: "Invalid project archive",
+		Error:   reason,
+	})
+}
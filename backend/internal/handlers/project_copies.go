@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DuplicateProject handles POST /api/projects/:id/duplicate - deep-copy a project
+// with its scene, objects and asset files. Shared links, comments and revision
+// history are not copied.
+func (h *ProjectHandler) DuplicateProject(c *gin.Context) {
+	h.copyProjectFromRequest(c, false)
+}
+
+// SaveProjectAsTemplate handles POST /api/projects/:id/as-template - store a copy of
+// the project as a starter design that new projects can be created from
+func (h *ProjectHandler) SaveProjectAsTemplate(c *gin.Context) {
+	h.copyProjectFromRequest(c, true)
+}
+
+// copyProjectFromRequest copies the project identified by the :id route
+// parameter, as a starter design if asTemplate is set, and writes the response
+func (h *ProjectHandler) copyProjectFromRequest(c *gin.Context, asTemplate bool) {
+	db := config.GetDB()
+
+	var req models.ProjectCopyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	source, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+	if err := db.Preload("Assets.Renditions").Preload("Objects").First(source, source.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	title := source.Title
+	if !asTemplate {
+		title += " (copy)"
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) != "" {
+		title = strings.TrimSpace(*req.Title)
+	}
+
+	// Duplicating a starter design yields another starter design
+	project, ok := copyProject(c, source, title, asTemplate || source.IsTemplate, copyStoredAssetFiles)
+	if !ok {
+		return
+	}
+
+	message := "Project duplicated successfully"
+	if asTemplate {
+		message = "Project saved as template successfully"
+	}
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    project,
+	})
+}
+
+// createProjectFromTemplate handles the template_id variant of CreateProject:
+// the new project starts as a copy of one of the creator's starter designs
+func createProjectFromTemplate(c *gin.Context, req *models.ProjectCreateRequest) {
+	db := config.GetDB()
+
+	if len(req.ProjectData) > 0 && string(req.ProjectData) != "null" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   "project_data cannot be combined with template_id",
+		})
+		return
+	}
+
+	var source models.Project
+	if err := db.Scopes(ownedProjects(c)).Where("is_template = ?", true).
+		Preload("Assets.Renditions").Preload("Objects").
+		First(&source, *req.TemplateID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid template",
+			Error:   "Template not found",
+		})
+		return
+	}
+
+	// A different frame may be chosen as long as the design still fits it
+	if req.FrameTemplateID != nil || req.FrameSize != nil {
+		template, ok := resolveFrameTemplate(c, req.FrameTemplateID, req.FrameSize)
+		if !ok {
+			return
+		}
+		if !sceneAndObjectsFitFrame(c, source.ProjectData, source.Objects, template) {
+			return
+		}
+		source.FrameTemplateID = &template.ID
+		source.FrameSize = template.SizeLabel()
+	}
+
+	project, ok := copyProject(c, &source, req.Title, false, copyStoredAssetFiles)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Project created successfully",
+		Data:    project,
+	})
+}
+
+// assetFiles stores the files of one of the source project's assets under the
+// new project and returns the unsaved asset record with the keys written
+type assetFiles func(ctx context.Context, projectID uint, asset *models.Asset) (models.Asset, []string, error)
+
+// copyStoredAssetFiles copies an existing asset's original and renditions in
+// storage, so the copies stay valid when either project is edited or deleted
+func copyStoredAssetFiles(ctx context.Context, projectID uint, asset *models.Asset) (models.Asset, []string, error) {
+	store := config.GetStorage()
+
+	uniqueID := uuid.New().String()
+	key := fmt.Sprintf("projects/%d/assets/%s.png", projectID, uniqueID)
+	if err := store.Copy(ctx, asset.FilePath, key); err != nil {
+		return models.Asset{}, nil, fmt.Errorf("copying asset %d: %w", asset.ID, err)
+	}
+	copied := []string{key}
+
+	renditions := make([]models.AssetRendition, 0, len(asset.Renditions))
+	for _, rendition := range asset.Renditions {
+		renditionKey := fmt.Sprintf("projects/%d/assets/%s_%d.png", projectID, uniqueID, rendition.Size)
+		if err := store.Copy(ctx, rendition.FilePath, renditionKey); err != nil {
+			deleteStoredKeys(ctx, copied)
+			return models.Asset{}, nil, fmt.Errorf("copying asset %d rendition: %w", asset.ID, err)
+		}
+		copied = append(copied, renditionKey)
+
+		renditions = append(renditions, models.AssetRendition{
+			Size:     rendition.Size,
+			Width:    rendition.Width,
+			Height:   rendition.Height,
+			FilePath: renditionKey,
+		})
+	}
+
+	return models.Asset{
+		ProjectID:  projectID,
+		Filename:   asset.Filename,
+		FilePath:   key,
+		Size:       asset.Size,
+		Width:      asset.Width,
+		Height:     asset.Height,
+		HasAlpha:   asset.HasAlpha,
+		UploadedAt: asset.UploadedAt,
+		Renditions: renditions,
+	}, copied, nil
+}
+
+// copyProject creates a new project for the current creator from source, which
+// must have its assets, renditions and objects loaded. files stores each asset's
+// files under the new project; scene and object references are remapped to the
+// new asset IDs. On failure it writes the error response and returns false.
+func copyProject(c *gin.Context, source *models.Project, title string, isTemplate bool, files assetFiles) (*models.Project, bool) {
+	db := config.GetDB()
+
+	if len(source.Assets) > 0 && !config.IsStorageAvailable() {
+		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Message: "File storage service unavailable",
+			Error:   "Project assets cannot be copied without storage",
+		})
+		return nil, false
+	}
+
+	project := models.Project{
+		OwnerID:         currentUserID(c),
+		Title:           title,
+		FrameTemplateID: source.FrameTemplateID,
+		FrameSize:       source.FrameSize,
+		ProjectData:     source.ProjectData,
+		IsTemplate:      isTemplate,
+		Version:         1,
+	}
+
+	ctx := context.Background()
+	var copied []string
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+
+		assetIDs := make(map[uint]uint, len(source.Assets))
+		refs := make(map[scene.Ref]scene.Ref, len(source.Assets))
+		for i := range source.Assets {
+			asset := &source.Assets[i]
+			clone, keys, err := files(ctx, project.ID, asset)
+			if err != nil {
+				return err
+			}
+			copied = append(copied, keys...)
+
+			if err := tx.Create(&clone).Error; err != nil {
+				return err
+			}
+			assetIDs[asset.ID] = clone.ID
+			refs[scene.Ref(strconv.FormatUint(uint64(asset.ID), 10))] = scene.Ref(strconv.FormatUint(uint64(clone.ID), 10))
+		}
+
+		for _, object := range source.Objects {
+			clone := models.Object{
+				ProjectID:  project.ID,
+				Position:   object.Position,
+				Layers:     object.Layers,
+				Properties: object.Properties,
+				SortOrder:  object.SortOrder,
+			}
+			if object.AssetID != nil {
+				if id, ok := assetIDs[*object.AssetID]; ok {
+					clone.AssetID = &id
+				}
+			}
+			if err := tx.Create(&clone).Error; err != nil {
+				return err
+			}
+		}
+
+		// Point the scene at the copied assets
+		projectData, err := scene.RemapAssets(source.ProjectData, refs)
+		if err != nil {
+			return err
+		}
+		project.ProjectData = projectData
+		if err := tx.Model(&project).Update("project_data", projectData).Error; err != nil {
+			return err
+		}
+
+		return recordRevision(tx, &project, project.OwnerID, "create", nil)
+	})
+	if err != nil {
+		deleteStoredKeys(ctx, copied)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to create project",
+			Error:   err.Error(),
+		})
+		return nil, false
+	}
+
+	var created models.Project
+	if err := db.Preload("FrameTemplate").Preload("Assets.Renditions").Preload("Objects").First(&created, project.ID).Error; err != nil {
+		// The copy exists; fall back to what was written
+		return &project, true
+	}
+	return &created, true
+}
@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/jsonpatch"
 	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // ProjectHandler handles project-related HTTP requests
@@ -18,9 +25,11 @@ func NewProjectHandler() *ProjectHandler {
 	return &ProjectHandler{}
 }
 
-// GetProjects handles GET /api/projects - list all projects with pagination
+// GetProjects handles GET /api/projects - list all projects with pagination.
+// ?templates=true lists the creator's starter designs instead.
 func (h *ProjectHandler) GetProjects(c *gin.Context) {
 	db := config.GetDB()
+	templates := c.Query("templates") == "true"
 
 	// Parse pagination parameters
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -39,7 +48,7 @@ func (h *ProjectHandler) GetProjects(c *gin.Context) {
 	var total int64
 
 	// Get total count
-	if err := db.Model(&models.Project{}).Count(&total).Error; err != nil {
+	if err := db.Model(&models.Project{}).Scopes(ownedProjects(c)).Where("is_template = ?", templates).Count(&total).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Message: "Failed to count projects",
@@ -49,7 +58,7 @@ func (h *ProjectHandler) GetProjects(c *gin.Context) {
 	}
 
 	// Get projects with pagination
-	if err := db.Offset(offset).Limit(limit).Order("created_at DESC").Find(&projects).Error; err != nil {
+	if err := db.Scopes(ownedProjects(c)).Where("is_template = ?", templates).Preload("FrameTemplate").Offset(offset).Limit(limit).Order("created_at DESC").Find(&projects).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Message: "Failed to fetch projects",
@@ -90,7 +99,7 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 	}
 
 	var project models.Project
-	if err := db.Preload("Assets").Preload("Objects").First(&project, id).Error; err != nil {
+	if err := db.Scopes(ownedProjects(c)).Preload("FrameTemplate").Preload("Assets.Renditions").Preload("Objects").First(&project, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Project not found",
@@ -99,6 +108,7 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", projectETag(&project))
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Message: "Project fetched successfully",
@@ -120,13 +130,45 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
+	if req.TemplateID != nil {
+		createProjectFromTemplate(c, &req)
+		return
+	}
+
+	template, ok := resolveFrameTemplate(c, req.FrameTemplateID, req.FrameSize)
+	if !ok {
+		return
+	}
+
+	projectData := scene.Default()
+	if len(req.ProjectData) > 0 && string(req.ProjectData) != "null" {
+		normalized, ok := normalizeProjectData(c, req.ProjectData)
+		if !ok {
+			return
+		}
+		projectData = normalized
+	}
+	if !sceneFitsFrame(c, projectData, template) {
+		return
+	}
+
 	project := models.Project{
-		Title:       req.Title,
-		FrameSize:   req.FrameSize,
-		ProjectData: req.ProjectData,
+		OwnerID:         currentUserID(c),
+		Title:           req.Title,
+		FrameTemplateID: &template.ID,
+		FrameSize:       template.SizeLabel(),
+		ProjectData:     projectData,
+		Version:         1,
 	}
 
-	if err := db.Create(&project).Error; err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, &project, project.OwnerID, "create", nil)
+	})
+	project.FrameTemplate = template
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Message: "Failed to create project",
@@ -135,6 +177,7 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", projectETag(&project))
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success: true,
 		Message: "Project created successfully",
@@ -167,7 +210,7 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	}
 
 	var project models.Project
-	if err := db.First(&project, id).Error; err != nil {
+	if err := db.Scopes(ownedProjects(c)).First(&project, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Project not found",
@@ -176,18 +219,159 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
+	// Reject the update if the client edited a stale copy
+	if !ifMatchSatisfied(c, &project) {
+		respondVersionConflict(c, project.ID)
+		return
+	}
+
 	// Update fields if provided
 	if req.Title != nil {
 		project.Title = *req.Title
 	}
-	if req.FrameSize != nil {
-		project.FrameSize = *req.FrameSize
+	template, err := projectFrameTemplate(db, &project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load frame template",
+			Error:   err.Error(),
+		})
+		return
+	}
+	frameChanged := req.FrameTemplateID != nil || req.FrameSize != nil
+	if frameChanged {
+		resolved, ok := resolveFrameTemplate(c, req.FrameTemplateID, req.FrameSize)
+		if !ok {
+			return
+		}
+		template = resolved
+		project.FrameTemplateID = &template.ID
+		project.FrameSize = template.SizeLabel()
 	}
 	if req.ProjectData != nil {
-		project.ProjectData = req.ProjectData
+		normalized, ok := normalizeProjectData(c, req.ProjectData)
+		if !ok {
+			return
+		}
+		project.ProjectData = normalized
+	}
+	if frameChanged {
+		// The Object rows were only checked against the previous frame
+		var rows []models.Object
+		if err := db.Scopes(orderedObjects).Where("project_id = ?", project.ID).Find(&rows).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to fetch objects",
+				Error:   err.Error(),
+			})
+			return
+		}
+		if !sceneAndObjectsFitFrame(c, project.ProjectData, rows, template) {
+			return
+		}
+	} else if !sceneFitsFrame(c, project.ProjectData, template) {
+		return
+	}
+	project.FrameTemplate = template
+
+	if err := commitProjectChange(db, &project, currentUserID(c), "update", nil); err != nil {
+		if errors.Is(err, errVersionConflict) {
+			respondVersionConflict(c, project.ID)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to update project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", projectETag(&project))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Project updated successfully",
+		Data:    project,
+	})
+}
+
+// PatchProject handles PATCH /api/projects/:id - apply an incremental change to the project data.
+// The body is an RFC 6902 JSON Patch (application/json-patch+json) or an RFC 7396
+// merge patch (application/merge-patch+json) applied to project_data.
+func (h *ProjectHandler) PatchProject(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil || len(body) == 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   "Patch body is required",
+		})
+		return
+	}
+
+	if !ifMatchSatisfied(c, project) {
+		respondVersionConflict(c, project.ID)
+		return
+	}
+
+	var patched []byte
+	switch c.ContentType() {
+	case "application/json-patch+json":
+		patched, err = jsonpatch.Apply(project.ProjectData, body)
+	case "application/merge-patch+json":
+		patched, err = jsonpatch.MergePatch(project.ProjectData, body)
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, models.APIResponse{
+			Success: false,
+			Message: "Unsupported patch format",
+			Error:   "Content-Type must be application/json-patch+json or application/merge-patch+json",
+		})
+		return
+	}
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, models.APIResponse{
+			Success: false,
+			Message: "Failed to apply patch",
+			Error:   err.Error(),
+		})
+		return
 	}
 
-	if err := db.Save(&project).Error; err != nil {
+	normalized, ok := normalizeProjectData(c, patched)
+	if !ok {
+		return
+	}
+	template, err := projectFrameTemplate(db, project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load frame template",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if !sceneFitsFrame(c, normalized, template) {
+		return
+	}
+	project.ProjectData = normalized
+	project.FrameTemplate = template
+
+	if err := commitProjectChange(db, project, currentUserID(c), "patch", nil); err != nil {
+		if errors.Is(err, errVersionConflict) {
+			respondVersionConflict(c, project.ID)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Message: "Failed to update project",
@@ -196,6 +380,7 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", projectETag(project))
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Message: "Project updated successfully",
@@ -218,7 +403,7 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	}
 
 	var project models.Project
-	if err := db.First(&project, id).Error; err != nil {
+	if err := db.Scopes(ownedProjects(c)).First(&project, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Project not found",
@@ -242,3 +427,128 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 		Message: "Project deleted successfully",
 	})
 }
+
+// normalizeProjectData upgrades scene data to the current schema version and
+// validates it. On failure it writes a 400 response listing the invalid fields.
+func normalizeProjectData(c *gin.Context, raw json.RawMessage) (json.RawMessage, bool) {
+	normalized, err := scene.Normalize(raw)
+	if err != nil {
+		response := models.APIResponse{
+			Success: false,
+			Message: "Invalid project data",
+			Error:   err.Error(),
+		}
+
+		var fieldErrs scene.ValidationErrors
+		if errors.As(err, &fieldErrs) {
+			response.Details = fieldErrs
+		}
+
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	return normalized, true
+}
+
+// errVersionConflict is returned when a project was modified after it was read
+var errVersionConflict = errors.New("project was modified by another request")
+
+// projectETag returns the entity tag identifying the project's current version
+func projectETag(project *models.Project) string {
+	return fmt.Sprintf("\"%d-%d\"", project.ID, project.Version)
+}
+
+// ifMatchSatisfied reports whether the request's If-Match header, if any,
+// matches the project's current ETag
+func ifMatchSatisfied(c *gin.Context, project *models.Project) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || header == "*" {
+		return true
+	}
+
+	current := projectETag(project)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// saveProjectVersion writes the project's editable fields and bumps its version,
+// failing with errVersionConflict if another request saved it first
+func saveProjectVersion(db *gorm.DB, project *models.Project) error {
+	expected := project.Version
+	project.Version = expected + 1
+
+	result := db.Model(project).
+		Where("version = ?", expected).
+		Select("Title", "FrameTemplateID", "FrameSize", "ProjectData", "Version", "UpdatedAt").
+		Updates(project)
+	if result.Error != nil {
+		project.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		project.Version = expected
+		return errVersionConflict
+	}
+	return nil
+}
+
+// recordRevision stores an immutable snapshot of the project's current version,
+// including its Object rows
+func recordRevision(tx *gorm.DB, project *models.Project, authorID uint, action string, restoredFrom *int) error {
+	var objects []models.Object
+	if err := tx.Scopes(orderedObjects).Where("project_id = ?", project.ID).Find(&objects).Error; err != nil {
+		return err
+	}
+
+	revision := models.ProjectRevision{
+		ProjectID:       project.ID,
+		Number:          project.Version,
+		AuthorID:        authorID,
+		Action:          action,
+		RestoredFrom:    restoredFrom,
+		Title:           project.Title,
+		FrameTemplateID: project.FrameTemplateID,
+		FrameSize:       project.FrameSize,
+		ProjectData:     project.ProjectData,
+		Objects:         models.SnapshotObjects(objects),
+	}
+	return tx.Create(&revision).Error
+}
+
+// commitProjectChange saves the project as a new version and records the
+// matching revision in a single transaction
+func commitProjectChange(db *gorm.DB, project *models.Project, authorID uint, action string, restoredFrom *int) error {
+	expected := project.Version
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := saveProjectVersion(tx, project); err != nil {
+			return err
+		}
+		return recordRevision(tx, project, authorID, action, restoredFrom)
+	})
+	if err != nil {
+		project.Version = expected
+	}
+	return err
+}
+
+// respondVersionConflict writes a 412 response carrying the current server
+// copy of the project so the client can merge and retry
+func respondVersionConflict(c *gin.Context, projectID uint) {
+	var current models.Project
+	if err := config.GetDB().Preload("FrameTemplate").Preload("Assets.Renditions").Preload("Objects").First(&current, projectID).Error; err == nil {
+		c.Header("ETag", projectETag(&current))
+	}
+
+	c.JSON(http.StatusPreconditionFailed, models.APIResponse{
+		Success: false,
+		Message: "Project has been modified",
+		Error:   "The project was changed since it was loaded; reload it and reapply your changes",
+		Data:    current,
+	})
+}
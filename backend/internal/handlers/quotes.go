@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/pricing"
+	"scrapyuk-backend/internal/production"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceRateHandler handles price table-related HTTP requests
+type PriceRateHandler struct{}
+
+// NewPriceRateHandler creates a new price rate handler
+func NewPriceRateHandler() *PriceRateHandler {
+	return &PriceRateHandler{}
+}
+
+// GetPriceRates handles GET /api/price-rates - list the prices quotes are calculated from
+func (h *PriceRateHandler) GetPriceRates(c *gin.Context) {
+	var rates []models.PriceRate
+	if err := config.GetDB().Order("id ASC").Find(&rates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch price rates",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Price rates fetched successfully",
+		Data: map[string]interface{}{
+			"currency": config.GetQuoteCurrency(),
+			"rates":    rates,
+		},
+	})
+}
+
+// UpdatePriceRate handles PUT /api/price-rates/:key - change a price (admins only)
+func (h *PriceRateHandler) UpdatePriceRate(c *gin.Context) {
+	db := config.GetDB()
+
+	var rate models.PriceRate
+	if err := db.Where("key = ?", c.Param("key")).First(&rate).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Price rate not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req models.PriceRateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rate.UnitPrice = *req.UnitPrice
+	if req.Name != nil {
+		if name := strings.TrimSpace(*req.Name); name != "" {
+			rate.Name = name
+		}
+	}
+
+	if err := db.Save(&rate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to update price rate",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Price rate updated successfully",
+		Data:    rate,
+	})
+}
+
+// GetProjectQuote handles GET /api/projects/:id/quote - price the materials
+// needed to build a project
+func (h *ProjectHandler) GetProjectQuote(c *gin.Context) {
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+	if err := config.GetDB().Preload("FrameTemplate").Preload("Assets").Preload("Objects", orderedObjects).First(project, project.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	plan, ok := buildProductionPlan(c, project, production.DefaultDPI)
+	if !ok {
+		return
+	}
+
+	quote, err := quotePlan(project, plan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to calculate quote",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Quote calculated successfully",
+		Data:    quote,
+	})
+}
+
+// projectQuote prices a project loaded with its frame template, assets and objects
+func projectQuote(project *models.Project) (*pricing.Quote, error) {
+	plan, err := productionPlan(project, production.DefaultDPI)
+	if err != nil {
+		return nil, err
+	}
+	return quotePlan(project, plan)
+}
+
+// quotePlan prices a project's production plan with the current price table
+func quotePlan(project *models.Project, plan *production.Plan) (*pricing.Quote, error) {
+	doc, err := scene.Parse(project.ProjectData)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.PriceRate
+	if err := config.GetDB().Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	rates := make([]pricing.Rate, len(rows))
+	for i, row := range rows {
+		rates[i] = pricing.Rate{Key: row.Key, Name: row.Name, Unit: row.Unit, UnitPrice: row.UnitPrice}
+	}
+
+	quote := pricing.Calculate(pricing.Input{
+		Plan:     plan,
+		Lighting: doc.Settings.Lighting,
+		Layout:   nestLayers(plan, pricing.SheetOptions),
+	}, rates, config.GetQuoteCurrency())
+	return &quote, nil
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RevisionHandler handles project revision history requests
+type RevisionHandler struct{}
+
+// NewRevisionHandler creates a new revision handler
+func NewRevisionHandler() *RevisionHandler {
+	return &RevisionHandler{}
+}
+
+// GetRevisions handles GET /api/projects/:id/revisions - list a project's revisions, newest first
+func (h *RevisionHandler) GetRevisions(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	// Snapshots can be large, so the list only carries metadata
+	var revisions []models.ProjectRevision
+	if err := db.Omit("ProjectData", "Objects").Preload("Author").
+		Where("project_id = ?", project.ID).
+		Order("number DESC").
+		Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch revisions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Revisions fetched successfully",
+		Data:    revisions,
+	})
+}
+
+// GetRevision handles GET /api/projects/:id/revisions/:number - get a single revision snapshot
+func (h *RevisionHandler) GetRevision(c *gin.Context) {
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := findRevision(c, project.ID, c.Param("number"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Revision fetched successfully",
+		Data:    revision,
+	})
+}
+
+// DiffRevisions handles GET /api/projects/:id/revisions/diff?from=&to= - compare two revisions
+func (h *RevisionHandler) DiffRevisions(c *gin.Context) {
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	from, ok := findRevision(c, project.ID, c.Query("from"))
+	if !ok {
+		return
+	}
+
+	// Default to comparing against the current head
+	to := c.Query("to")
+	if to == "" {
+		to = strconv.Itoa(project.Version)
+	}
+	target, ok := findRevision(c, project.ID, to)
+	if !ok {
+		return
+	}
+
+	fromDoc, err := scene.Parse(from.ProjectData)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Revision data could not be parsed",
+			Error:   err.Error(),
+		})
+		return
+	}
+	toDoc, err := scene.Parse(target.ProjectData)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Revision data could not be parsed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	fromDoc.Objects = append(fromDoc.Objects, sceneObjectsFromRows(revisionObjects(from))...)
+	toDoc.Objects = append(toDoc.Objects, sceneObjectsFromRows(revisionObjects(target))...)
+
+	var changedFields []string
+	if from.Title != target.Title {
+		changedFields = append(changedFields, "title")
+	}
+	if !equalUintPtr(from.FrameTemplateID, target.FrameTemplateID) {
+		changedFields = append(changedFields, "frame_template_id")
+	}
+	if from.FrameSize != target.FrameSize {
+		changedFields = append(changedFields, "frame_size")
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Revisions compared successfully",
+		Data: map[string]interface{}{
+			"from":           from.Number,
+			"to":             target.Number,
+			"project_fields": changedFields,
+			"scene":          scene.Compare(fromDoc, toDoc),
+		},
+	})
+}
+
+// RestoreRevision handles POST /api/projects/:id/revisions/:number/restore - make a revision the new head
+func (h *RevisionHandler) RestoreRevision(c *gin.Context) {
+	db := config.GetDB()
+
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := findRevision(c, project.ID, c.Param("number"))
+	if !ok {
+		return
+	}
+
+	if !ifMatchSatisfied(c, project) {
+		respondVersionConflict(c, project.ID)
+		return
+	}
+
+	// Revisions saved before frame templates existed only carry a frame size;
+	// those keep the project's current frame if no template has that size
+	template, err := projectFrameTemplate(db, &models.Project{FrameTemplateID: revision.FrameTemplateID})
+	if err == nil && template == nil {
+		template, err = frameTemplateForSize(db, revision.FrameSize)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			template, err = projectFrameTemplate(db, project)
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Frame template not found",
+			Error:   "The frame this revision was built for no longer exists",
+		})
+		return
+	}
+
+	// Restoring creates a new version rather than rewriting history
+	project.Title = revision.Title
+	project.ProjectData = revision.ProjectData
+	if upgraded, err := scene.Upgrade(project.ProjectData); err == nil {
+		project.ProjectData = upgraded
+	}
+	// Revisions that recorded Object rows bring them back too; older ones
+	// leave the current rows in place
+	objects, err := restoredObjects(db, project, revision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to restore revision",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if template != nil {
+		project.FrameTemplateID = &template.ID
+		project.FrameSize = template.SizeLabel()
+		doc, err := scene.Parse(project.ProjectData)
+		if err != nil {
+			respondMisfit(c, err)
+			return
+		}
+		doc.Objects = append(doc.Objects, sceneObjectsFromRows(objects)...)
+		if !documentFitsFrame(c, doc, template) {
+			return
+		}
+	} else {
+		project.FrameSize = revision.FrameSize
+	}
+	project.FrameTemplate = template
+
+	restoredFrom := revision.Number
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if revision.Objects != nil {
+			if err := tx.Where("project_id = ?", project.ID).Delete(&models.Object{}).Error; err != nil {
+				return err
+			}
+			for i := range objects {
+				if err := tx.Create(&objects[i]).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return commitProjectChange(tx, project, currentUserID(c), "restore", &restoredFrom)
+	})
+	if err != nil {
+		if errors.Is(err, errVersionConflict) {
+			respondVersionConflict(c, project.ID)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to restore revision",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", projectETag(project))
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Revision restored successfully",
+		Data:    project,
+	})
+}
+
+// restoredObjects returns the Object rows a project has once the revision is
+// restored: the rows the revision recorded, or the current rows for revisions
+// that recorded none. Recorded rows keep their IDs unless another project has
+// taken them since, and lose references to assets that have been deleted.
+func restoredObjects(db *gorm.DB, project *models.Project, revision *models.ProjectRevision) ([]models.Object, error) {
+	if revision.Objects == nil {
+		var current []models.Object
+		err := db.Scopes(orderedObjects).Where("project_id = ?", project.ID).Find(&current).Error
+		return current, err
+	}
+
+	var assetIDs []uint
+	if err := db.Model(&models.Asset{}).Where("project_id = ?", project.ID).Pluck("id", &assetIDs).Error; err != nil {
+		return nil, err
+	}
+	assets := make(map[uint]bool, len(assetIDs))
+	for _, id := range assetIDs {
+		assets[id] = true
+	}
+
+	objects := revisionObjects(revision)
+	for i := range objects {
+		object := &objects[i]
+		var taken int64
+		if err := db.Model(&models.Object{}).Where("id = ? AND project_id <> ?", object.ID, project.ID).Count(&taken).Error; err != nil {
+			return nil, err
+		}
+		if taken > 0 {
+			object.ID = 0
+		}
+		if object.AssetID != nil && !assets[*object.AssetID] {
+			object.AssetID = nil
+		}
+	}
+	return objects, nil
+}
+
+// revisionObjects returns the Object rows a revision recorded, in render order
+func revisionObjects(revision *models.ProjectRevision) []models.Object {
+	objects := make([]models.Object, len(revision.Objects))
+	for i := range revision.Objects {
+		objects[i] = revision.Objects[i].Object(revision.ProjectID)
+	}
+	return objects
+}
+
+// findRevision loads the revision with the given number for a project. On
+// failure it writes the error response and returns false.
+func findRevision(c *gin.Context, projectID uint, number string) (*models.ProjectRevision, bool) {
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid revision number",
+			Error:   "Revision number must be a positive integer",
+		})
+		return nil, false
+	}
+
+	var revision models.ProjectRevision
+	if err := config.GetDB().Preload("Author").
+		Where("project_id = ? AND number = ?", projectID, n).
+		First(&revision).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Revision not found",
+			Error:   err.Error(),
+		})
+		return nil, false
+	}
+
+	return &revision, true
+}
+
+// equalUintPtr reports whether two optional IDs are both unset or equal
+func equalUintPtr(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
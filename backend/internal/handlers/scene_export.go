@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/gltf"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"github.com/gin-gonic/gin"
+)
+
+// glbTextureSize is the rendition embedded as an object's texture; smaller
+// originals are embedded as they are
+const glbTextureSize = 1024
+
+// ExportGLB handles GET /api/projects/:id/export.glb - download the assembled
+// scene as a binary glTF model: the frame, every layer as a textured quad and
+// the LED lights
+func (h *ProjectHandler) ExportGLB(c *gin.Context) {
+	project, ok := findOwnedProject(c)
+	if !ok {
+		return
+	}
+	err := config.GetDB().Preload("FrameTemplate").Preload("Assets.Renditions").Preload("Objects", orderedObjects).
+		First(project, project.ID).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to load project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	template := project.FrameTemplate
+	if template == nil {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Project has no frame template",
+			Error:   "Choose a frame template so the scene can be assembled in it",
+		})
+		return
+	}
+	doc, err := scene.Parse(project.ProjectData)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Invalid project data",
+			Error:   err.Error(),
+		})
+		return
+	}
+	doc.Objects = append(doc.Objects, sceneObjectsFromRows(project.Objects)...)
+
+	// Only assets placed in the scene are embedded
+	placed := make(map[scene.Ref]bool)
+	for _, obj := range doc.Objects {
+		if obj.AssetID != "" {
+			placed[obj.AssetID] = true
+		}
+	}
+	var used []*models.Asset
+	for i := range project.Assets {
+		if placed[scene.Ref(strconv.FormatUint(uint64(project.Assets[i].ID), 10))] {
+			used = append(used, &project.Assets[i])
+		}
+	}
+	if len(used) > 0 && !config.IsStorageAvailable() {
+		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Message: "File storage service unavailable",
+		})
+		return
+	}
+
+	assets := make(map[scene.Ref]gltf.Asset, len(used))
+	for _, asset := range used {
+		png, err := glbTexture(c.Request.Context(), asset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to export project",
+				Error:   err.Error(),
+			})
+			return
+		}
+		assets[scene.Ref(strconv.FormatUint(uint64(asset.ID), 10))] = gltf.Asset{
+			Width:  asset.Width,
+			Height: asset.Height,
+			PNG:    png,
+		}
+	}
+
+	frame := gltf.Frame{
+		Name:         template.Name,
+		WidthCM:      template.WidthCM,
+		HeightCM:     template.HeightCM,
+		DepthCM:      template.DepthCM,
+		WallMaterial: template.WallMaterial,
+	}
+	for _, mount := range template.LEDMounts {
+		frame.LEDMounts = append(frame.LEDMounts, scene.Vector3{X: mount.X, Y: mount.Y, Z: mount.Z})
+	}
+
+	model, err := gltf.Build(doc, frame, assets)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to export project",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// The router defaults every response to JSON
+	c.Header("Content-Type", "model/gltf-binary")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%d.glb"`, downloadName(project), project.ID))
+	c.Data(http.StatusOK, "model/gltf-binary", model)
+}
+
+// glbTexture reads the PNG embedded for an asset: its glbTextureSize rendition,
+// or the original when it is no larger than that
+func glbTexture(ctx context.Context, asset *models.Asset) ([]byte, error) {
+	key := asset.FilePath
+	for _, rendition := range asset.Renditions {
+		if rendition.Size == glbTextureSize {
+			key = rendition.FilePath
+		}
+	}
+
+	object, _, err := config.GetStorage().Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("asset %d: %w", asset.ID, err)
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("asset %d: %w", asset.ID, err)
+	}
+	return data, nil
+}
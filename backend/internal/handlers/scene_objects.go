@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scene"
+
+	"gorm.io/gorm"
+)
+
+// A project's scene objects live in two places: the objects array of the scene
+// document the editor saves, and the Object rows managed through
+// /api/projects/:id/objects. Whatever reads the scene as a whole appends the
+// rows after the document's objects.
+
+// objectRowRefPrefix keeps the scene IDs of Object rows apart from the IDs the
+// editor gives the objects of the scene document
+const objectRowRefPrefix = "object-"
+
+// Editor defaults for properties an Object row leaves out, as the scene
+// migrations fill them in for document objects
+const (
+	defaultObjectScale        = 1.0
+	defaultObjectLayerSpacing = 0.5
+)
+
+// sceneObjectsFromRows converts Object rows, already in render order, to scene
+// objects
+func sceneObjectsFromRows(rows []models.Object) []scene.Object {
+	objects := make([]scene.Object, 0, len(rows))
+	for i := range rows {
+		objects = append(objects, sceneObjectFromRow(&rows[i]))
+	}
+	return objects
+}
+
+// sceneObjectFromRow converts an Object row to a scene object. Its position and
+// properties were validated when the row was saved.
+func sceneObjectFromRow(row *models.Object) scene.Object {
+	obj := scene.Object{
+		ID:           scene.Ref(objectRowRefPrefix + strconv.FormatUint(uint64(row.ID), 10)),
+		Scale:        defaultObjectScale,
+		Layers:       max(row.Layers, 1),
+		LayerSpacing: defaultObjectLayerSpacing,
+	}
+	if row.AssetID != nil {
+		obj.AssetID = scene.Ref(strconv.FormatUint(uint64(*row.AssetID), 10))
+	}
+	json.Unmarshal(row.Position, &obj.Position)
+
+	var props objectProperties
+	if len(row.Properties) > 0 && json.Unmarshal(row.Properties, &props) == nil {
+		if props.Scale != nil {
+			obj.Scale = *props.Scale
+		}
+		if props.LayerSpacing != nil {
+			obj.LayerSpacing = *props.LayerSpacing
+		}
+		if props.Rotation != nil {
+			obj.Rotation = &scene.Vector3{X: props.Rotation.X, Y: props.Rotation.Y, Z: props.Rotation.Z}
+		}
+	}
+	return obj
+}
+
+// orderedObjects sorts Object rows in render order
+func orderedObjects(tx *gorm.DB) *gorm.DB {
+	return tx.Order("sort_order ASC, id ASC")
+}
+
+// appendObjectRows adds the project's Object rows, in render order, after the
+// objects of its scene document
+func appendObjectRows(db *gorm.DB, projectID uint, doc *scene.Document) error {
+	var rows []models.Object
+	if err := db.Scopes(orderedObjects).Where("project_id = ?", projectID).Find(&rows).Error; err != nil {
+		return err
+	}
+	doc.Objects = append(doc.Objects, sceneObjectsFromRows(rows)...)
+	return nil
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxAccessFieldLength caps stored user agents and referrers
+	maxAccessFieldLength = 512
+	// maxAnalyticsBuckets bounds the size of a requested time series
+	maxAnalyticsBuckets = 1000
+	// recentAccessLimit is how many audit entries the analytics response includes
+	recentAccessLimit = 20
+)
+
+// AnalyticsBucket is one point of a shared link's view time series
+type AnalyticsBucket struct {
+	Start         time.Time `json:"start"`
+	Views         int       `json:"views"`
+	UniqueViewers int       `json:"unique_viewers"`
+}
+
+// GetSharedLinkAnalytics handles GET /api/shared-links/:token/analytics - view
+// counts, unique viewers and a time series for a shared link.
+// Query parameters: interval ("day" or "hour", default "day"), from and to (RFC 3339).
+func (h *SharedLinkHandler) GetSharedLinkAnalytics(c *gin.Context) {
+	db := config.GetDB()
+
+	var sharedLink models.SharedLink
+	if err := db.Scopes(ownedThroughProject(c, "shared_links")).Where("shared_links.token = ?", c.Param("token")).First(&sharedLink).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Shared link not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	interval := c.DefaultQuery("interval", "day")
+	var step time.Duration
+	switch interval {
+	case "day":
+		step = 24 * time.Hour
+	case "hour":
+		step = time.Hour
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid interval",
+			Error:   "interval must be \"day\" or \"hour\"",
+		})
+		return
+	}
+
+	// Default to the last 30 days, or the last 48 hours for hourly series
+	to := time.Now().UTC()
+	from := to.Add(-30 * step)
+	if interval == "hour" {
+		from = to.Add(-48 * step)
+	}
+	for name, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid " + name + " parameter",
+				Error:   "Times must be RFC 3339, e.g. 2024-01-31T00:00:00Z",
+			})
+			return
+		}
+		*target = parsed.UTC()
+	}
+
+	from = from.Truncate(step)
+	if !from.Before(to) || int(to.Sub(from)/step) >= maxAnalyticsBuckets {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid time range",
+			Error:   "from must be before to and the range must not exceed 1000 intervals",
+		})
+		return
+	}
+
+	// Totals over the link's whole lifetime
+	var uniqueViewers int64
+	if err := db.Model(&models.SharedLinkAccess{}).
+		Where("shared_link_id = ? AND outcome = ?", sharedLink.ID, models.AccessOutcomeSuccess).
+		Distinct("ip_hash").Count(&uniqueViewers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch analytics",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var outcomeRows []struct {
+		Outcome string
+		Count   int
+	}
+	if err := db.Model(&models.SharedLinkAccess{}).
+		Select("outcome, COUNT(*) AS count").
+		Where("shared_link_id = ?", sharedLink.ID).
+		Group("outcome").Scan(&outcomeRows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch analytics",
+			Error:   err.Error(),
+		})
+		return
+	}
+	outcomes := map[string]int{
+		models.AccessOutcomeSuccess: 0,
+		models.AccessOutcomeExpired: 0,
+		models.AccessOutcomeDenied:  0,
+	}
+	for _, row := range outcomeRows {
+		outcomes[row.Outcome] = row.Count
+	}
+
+	// Time series of successful views within the requested range. Timestamps
+	// are stored in server-local time, so the bounds are compared in it too.
+	var views []models.SharedLinkAccess
+	if err := db.Select("ip_hash", "created_at").
+		Where("shared_link_id = ? AND outcome = ? AND created_at >= ? AND created_at < ?",
+			sharedLink.ID, models.AccessOutcomeSuccess, from.Local(), to.Local()).
+		Find(&views).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch analytics",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	series := make([]AnalyticsBucket, 0, int(to.Sub(from)/step)+1)
+	for start := from; start.Before(to); start = start.Add(step) {
+		series = append(series, AnalyticsBucket{Start: start})
+	}
+	viewers := make([]map[string]bool, len(series))
+	for _, view := range views {
+		i := int(view.CreatedAt.UTC().Sub(from) / step)
+		if i < 0 || i >= len(series) {
+			continue
+		}
+		series[i].Views++
+		if viewers[i] == nil {
+			viewers[i] = map[string]bool{}
+		}
+		if !viewers[i][view.IPHash] {
+			viewers[i][view.IPHash] = true
+			series[i].UniqueViewers++
+		}
+	}
+
+	var recent []models.SharedLinkAccess
+	if err := db.Where("shared_link_id = ?", sharedLink.ID).
+		Order("created_at DESC").Limit(recentAccessLimit).
+		Find(&recent).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch analytics",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Shared link analytics fetched successfully",
+		Data: map[string]interface{}{
+			"token":           sharedLink.Token,
+			"views":           outcomes[models.AccessOutcomeSuccess],
+			"unique_viewers":  uniqueViewers,
+			"outcomes":        outcomes,
+			"last_viewed_at":  sharedLink.LastViewedAt,
+			"interval":        interval,
+			"from":            from,
+			"to":              to,
+			"series":          series,
+			"recent_accesses": recent,
+		},
+	})
+}
+
+// recordSharedLinkAccess writes an audit entry for an attempt to open a shared
+// link. Failures are logged rather than surfaced to the viewer. The client IP
+// comes from forwarded headers only behind TRUSTED_PROXIES, so viewers cannot
+// inflate unique_viewers by sending their own X-Forwarded-For.
+func recordSharedLinkAccess(c *gin.Context, sharedLink *models.SharedLink, outcome, reason string) {
+	access := models.SharedLinkAccess{
+		SharedLinkID: sharedLink.ID,
+		IPHash:       hashClientIP(c.ClientIP()),
+		UserAgent:    truncate(c.Request.UserAgent(), maxAccessFieldLength),
+		Referrer:     truncate(c.Request.Referer(), maxAccessFieldLength),
+		Outcome:      outcome,
+		Reason:       reason,
+	}
+
+	if err := config.GetDB().Create(&access).Error; err != nil {
+		log.Printf("Failed to record access to shared link %d: %v", sharedLink.ID, err)
+	}
+}
+
+// hashClientIP returns a keyed hash of an IP address so stored hashes cannot be
+// reversed by enumerating the address space
+func hashClientIP(ip string) string {
+	mac := hmac.New(sha256.New, config.GetJWTSecret())
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return strings.ToValidUTF8(s[:n], "")
+	}
+	return s
+}
@@ -1,15 +1,22 @@
 package handlers
 
 import (
+	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/auth"
 	"scrapyuk-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // SharedLinkHandler handles shared link-related HTTP requests
@@ -36,7 +43,7 @@ func (h *SharedLinkHandler) CreateSharedLink(c *gin.Context) {
 
 	// Verify project exists
 	var project models.Project
-	if err := db.First(&project, projectID).Error; err != nil {
+	if err := db.Scopes(ownedProjects(c)).First(&project, projectID).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Project not found",
@@ -55,14 +62,51 @@ func (h *SharedLinkHandler) CreateSharedLink(c *gin.Context) {
 		return
 	}
 
+	cidrs, err := normalizeCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	domains, err := normalizeEmailDomains(req.AllowedEmailDomains)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
 	// Generate unique token
 	token := uuid.New().String()
 
 	// Create shared link
 	sharedLink := models.SharedLink{
-		ProjectID: uint(projectID),
-		Token:     token,
-		ExpiresAt: req.ExpiresAt,
+		ProjectID:           uint(projectID),
+		Token:               token,
+		ExpiresAt:           req.ExpiresAt,
+		MaxViews:            req.MaxViews,
+		AllowedCIDRs:        cidrs,
+		AllowedEmailDomains: domains,
+		ShowQuote:           req.ShowQuote,
+	}
+
+	if req.Password != nil {
+		hash, err := auth.HashPassword(*req.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to create shared link",
+				Error:   err.Error(),
+			})
+			return
+		}
+		sharedLink.PasswordHash = hash
 	}
 
 	if err := db.Create(&sharedLink).Error; err != nil {
@@ -88,40 +132,45 @@ func (h *SharedLinkHandler) CreateSharedLink(c *gin.Context) {
 func (h *SharedLinkHandler) GetSharedProject(c *gin.Context) {
 	db := config.GetDB()
 
-	token := c.Param("token")
-	if token == "" {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid token",
-			Error:   "Token cannot be empty",
-		})
+	sharedLink, ok := findAccessibleSharedLink(c, c.Param("token"))
+	if !ok {
 		return
 	}
 
-	// Find shared link by token
-	var sharedLink models.SharedLink
-	if err := db.Where("token = ?", token).First(&sharedLink).Error; err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
+	// Count the view, refusing it once the link's view limit is reached
+	now := time.Now()
+	result := db.Model(&models.SharedLink{}).
+		Where("id = ? AND (max_views IS NULL OR view_count < max_views)", sharedLink.ID).
+		Updates(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + 1"),
+			"last_viewed_at": now,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Message: "Shared link not found",
-			Error:   "Invalid or expired token",
+			Message: "Failed to record view",
+			Error:   result.Error.Error(),
 		})
 		return
 	}
-
-	// Check if link has expired
-	if sharedLink.ExpiresAt != nil && sharedLink.ExpiresAt.Before(time.Now()) {
+	if result.RowsAffected == 0 {
+		recordSharedLinkAccess(c, sharedLink, models.AccessOutcomeExpired, "view_limit")
 		c.JSON(http.StatusGone, models.APIResponse{
 			Success: false,
-			Message: "Shared link has expired",
+			Message: "Shared link view limit reached",
 			Error:   "This link is no longer valid",
 		})
 		return
 	}
+	sharedLink.ViewCount++
+	sharedLink.LastViewedAt = &now
+	recordSharedLinkAccess(c, sharedLink, models.AccessOutcomeSuccess, "")
 
 	// Get the project with related data
 	var project models.Project
-	if err := db.Preload("Assets").Preload("Objects").First(&project, sharedLink.ProjectID).Error; err != nil {
+	err := db.Preload("FrameTemplate").Preload("Assets.Renditions").Preload("Objects", orderedObjects).
+		First(&project, sharedLink.ProjectID).Error
+	if err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Project not found",
@@ -130,10 +179,26 @@ func (h *SharedLinkHandler) GetSharedProject(c *gin.Context) {
 		return
 	}
 
-	// Return project data for buyer view
+	// Buyers only get the sanitized view, never the stored project
+	view, err := buildBuyerProject(&project, sharedLink)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Project data could not be parsed",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if sharedLink.ShowQuote {
+		// A project that cannot be priced is still shown, just without a quote
+		if quote, err := projectQuote(&project); err == nil {
+			view.Quote = quote
+		}
+	}
+
 	response := map[string]interface{}{
-		"project":     project,
-		"shared_link": sharedLink,
+		"project":     view,
+		"shared_link": buildBuyerLink(sharedLink),
 		"is_shared":   true,
 	}
 
@@ -144,6 +209,87 @@ func (h *SharedLinkHandler) GetSharedProject(c *gin.Context) {
 	})
 }
 
+// UnlockSharedLink handles POST /api/shared/:token/unlock - verify a shared link's
+// password and/or email domain and issue a short-lived viewer token
+func (h *SharedLinkHandler) UnlockSharedLink(c *gin.Context) {
+	sharedLink, ok := lookupSharedLink(c, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	var req models.SharedLinkUnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Throttle guessing per link and client
+	attemptKey := sharedLink.Token + "|" + c.ClientIP()
+	if wait := unlockAttempts.retryAfter(attemptKey, time.Now()); wait > 0 {
+		recordSharedLinkAccess(c, sharedLink, models.AccessOutcomeDenied, "throttled")
+		c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, models.APIResponse{
+			Success: false,
+			Message: "Too many attempts",
+			Error:   "Too many failed unlock attempts, try again later",
+		})
+		return
+	}
+
+	if sharedLink.PasswordHash != "" && !auth.CheckPassword(sharedLink.PasswordHash, req.Password) {
+		unlockAttempts.fail(attemptKey, time.Now())
+		recordSharedLinkAccess(c, sharedLink, models.AccessOutcomeDenied, "password")
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Incorrect password",
+			Error:   "The password for this shared link is incorrect",
+		})
+		return
+	}
+
+	if len(sharedLink.AllowedEmailDomains) > 0 && !emailDomainAllowed(req.Email, sharedLink.AllowedEmailDomains) {
+		unlockAttempts.fail(attemptKey, time.Now())
+		recordSharedLinkAccess(c, sharedLink, models.AccessOutcomeDenied, "email_domain")
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "Email not allowed",
+			Error:   "This shared link is restricted to specific email domains",
+		})
+		return
+	}
+
+	unlockAttempts.reset(attemptKey)
+
+	viewerToken, expiresAt, err := auth.IssueViewerToken(config.GetJWTSecret(), sharedLink.Token, config.ViewerTokenTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to unlock shared link",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Scope the cookie to this link's routes so several unlocked links can coexist
+	secure := os.Getenv("GIN_MODE") == "release"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(config.ViewerCookieName, viewerToken, int(config.ViewerTokenTTL.Seconds()),
+		"/api/shared/"+sharedLink.Token, "", secure, true)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Shared link unlocked successfully",
+		Data: map[string]interface{}{
+			"viewer_token": viewerToken,
+			"expires_at":   expiresAt,
+		},
+	})
+}
+
 // GetProjectSharedLinks handles GET /api/projects/:id/shared-links - get all shared links for a project
 func (h *SharedLinkHandler) GetProjectSharedLinks(c *gin.Context) {
 	db := config.GetDB()
@@ -160,7 +306,7 @@ func (h *SharedLinkHandler) GetProjectSharedLinks(c *gin.Context) {
 
 	// Verify project exists
 	var project models.Project
-	if err := db.First(&project, projectID).Error; err != nil {
+	if err := db.Scopes(ownedProjects(c)).First(&project, projectID).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Project not found",
@@ -203,7 +349,7 @@ func (h *SharedLinkHandler) DeleteSharedLink(c *gin.Context) {
 
 	// Find and delete the shared link
 	var sharedLink models.SharedLink
-	if err := db.Where("token = ?", token).First(&sharedLink).Error; err != nil {
+	if err := db.Scopes(ownedThroughProject(c, "shared_links")).Where("shared_links.token = ?", token).First(&sharedLink).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Shared link not found",
@@ -227,7 +373,7 @@ func (h *SharedLinkHandler) DeleteSharedLink(c *gin.Context) {
 	})
 }
 
-// CleanupExpiredLinks handles cleanup of expired shared links (can be called via cron)
+// CleanupExpiredLinks handles cleanup of expired shared links (run periodically by the expired-link-cleanup job)
 func (h *SharedLinkHandler) CleanupExpiredLinks() error {
 	db := config.GetDB()
 
@@ -238,8 +384,166 @@ func (h *SharedLinkHandler) CleanupExpiredLinks() error {
 
 	// Log how many expired links were deleted
 	if result.RowsAffected > 0 {
-		println("Cleaned up", result.RowsAffected, "expired shared links")
+		log.Printf("Cleaned up %d expired shared links", result.RowsAffected)
 	}
 
 	return nil
 }
+
+// lookupSharedLink loads the shared link with the given token and enforces
+// expiry and network restrictions. Refused attempts are recorded in the link's
+// access log. On failure it writes the error response and returns false.
+func lookupSharedLink(c *gin.Context, token string) (*models.SharedLink, bool) {
+	if token == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid token",
+			Error:   "Token cannot be empty",
+		})
+		return nil, false
+	}
+
+	// Find shared link by token
+	var sharedLink models.SharedLink
+	if err := config.GetDB().Where("token = ?", token).First(&sharedLink).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Shared link not found",
+			Error:   "Invalid or expired token",
+		})
+		return nil, false
+	}
+
+	// Check if link has expired
+	if sharedLink.ExpiresAt != nil && sharedLink.ExpiresAt.Before(time.Now()) {
+		recordSharedLinkAccess(c, &sharedLink, models.AccessOutcomeExpired, "expired")
+		c.JSON(http.StatusGone, models.APIResponse{
+			Success: false,
+			Message: "Shared link has expired",
+			Error:   "This link is no longer valid",
+		})
+		return nil, false
+	}
+
+	// Check the viewer's network
+	if len(sharedLink.AllowedCIDRs) > 0 && !ipAllowed(c.ClientIP(), sharedLink.AllowedCIDRs) {
+		recordSharedLinkAccess(c, &sharedLink, models.AccessOutcomeDenied, "network")
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "Access denied",
+			Error:   "This shared link cannot be opened from your network",
+		})
+		return nil, false
+	}
+
+	return &sharedLink, true
+}
+
+// findAccessibleSharedLink is lookupSharedLink plus, for password- or
+// email-restricted links, verification of the viewer token issued on unlock
+func findAccessibleSharedLink(c *gin.Context, token string) (*models.SharedLink, bool) {
+	sharedLink, ok := lookupSharedLink(c, token)
+	if !ok {
+		return nil, false
+	}
+
+	if sharedLink.RequiresUnlock() {
+		viewerToken := c.GetHeader("X-Viewer-Token")
+		if viewerToken == "" {
+			viewerToken, _ = c.Cookie(config.ViewerCookieName)
+		}
+
+		if viewerToken == "" || auth.VerifyViewerToken(config.GetJWTSecret(), viewerToken, sharedLink.Token) != nil {
+			recordSharedLinkAccess(c, sharedLink, models.AccessOutcomeDenied, "locked")
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Message: "Shared link is locked",
+				Error:   "Unlock this shared link before viewing it",
+				Details: map[string]bool{
+					"requires_password": sharedLink.PasswordHash != "",
+					"requires_email":    len(sharedLink.AllowedEmailDomains) > 0,
+				},
+			})
+			return nil, false
+		}
+	}
+
+	return sharedLink, true
+}
+
+// normalizeCIDRs validates allowed networks, accepting bare IPs as single-host ranges
+func normalizeCIDRs(entries []string) (models.StringList, error) {
+	var cidrs models.StringList
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			cidrs = append(cidrs, network.String())
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address or CIDR range %q", entry)
+		}
+		if ip.To4() != nil {
+			cidrs = append(cidrs, ip.String()+"/32")
+		} else {
+			cidrs = append(cidrs, ip.String()+"/128")
+		}
+	}
+	return cidrs, nil
+}
+
+// normalizeEmailDomains lowercases allowed email domains and strips any leading "@"
+func normalizeEmailDomains(entries []string) (models.StringList, error) {
+	var domains models.StringList
+	for _, entry := range entries {
+		domain := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(entry), "@"))
+		if domain == "" {
+			continue
+		}
+		if strings.Contains(domain, "@") || !strings.Contains(domain, ".") {
+			return nil, fmt.Errorf("invalid email domain %q", entry)
+		}
+		domains = append(domains, domain)
+	}
+	return domains, nil
+}
+
+// ipAllowed reports whether ip falls inside any of the given CIDR ranges
+func ipAllowed(ip string, cidrs []string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, cidr := range cidrs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// emailDomainAllowed reports whether email belongs to one of the domains or their
+// subdomains. The address is typed by the buyer and not verified, so the check
+// is advisory: it keeps honest buyers on the right link but anyone can claim an
+// address on an allowed domain. Combine it with a password to restrict a link.
+func emailDomainAllowed(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+
+	for _, allowed := range domains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// Failed unlock attempts allowed per shared link and client IP within the window
+const (
+	maxUnlockFailures  = 5
+	unlockFailureReset = 15 * time.Minute
+)
+
+// unlockAttempts throttles password and email guessing on shared links. It
+// is kept in memory, so the count starts over when the server restarts.
+var unlockAttempts = &attemptLimiter{failures: make(map[string]*attemptWindow)}
+
+type attemptWindow struct {
+	count   int
+	started time.Time
+}
+
+// attemptLimiter counts failures per key within a fixed window
+type attemptLimiter struct {
+	mu       sync.Mutex
+	failures map[string]*attemptWindow
+}
+
+// retryAfter returns how long key must wait before trying again, or zero
+// when it may try now
+func (l *attemptLimiter) retryAfter(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window, ok := l.failures[key]
+	if !ok {
+		return 0
+	}
+	if now.Sub(window.started) >= unlockFailureReset {
+		delete(l.failures, key)
+		return 0
+	}
+	if window.count < maxUnlockFailures {
+		return 0
+	}
+	return window.started.Add(unlockFailureReset).Sub(now)
+}
+
+// fail records a failed attempt for key
+func (l *attemptLimiter) fail(key string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop expired windows so the map does not grow with every client seen
+	for k, window := range l.failures {
+		if now.Sub(window.started) >= unlockFailureReset {
+			delete(l.failures, k)
+		}
+	}
+
+	window, ok := l.failures[key]
+	if !ok {
+		window = &attemptWindow{started: now}
+		l.failures[key] = window
+	}
+	window.count++
+}
+
+// reset forgets the failures of key after a successful attempt
+func (l *attemptLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+}
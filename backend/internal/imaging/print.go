@@ -0,0 +1,53 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// Scale resamples img to exactly width x height pixels, preserving the alpha channel
+func Scale(img image.Image, width, height int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// EncodePrintPNG encodes img as a PNG carrying a pHYs chunk, so print and
+// cutting software lay it out at its physical size for the given DPI
+func EncodePrintPNG(img image.Image, dpi int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+
+	// The encoder always writes IHDR first: signature, then a 13-byte chunk
+	// framed by its length, type and CRC
+	ihdrEnd := len(pngSignature) + 4 + 4 + 13 + 4
+	if len(data) < ihdrEnd || string(data[len(pngSignature)+4:len(pngSignature)+8]) != "IHDR" {
+		return nil, fmt.Errorf("unexpected PNG layout")
+	}
+
+	// pHYs stores pixels per metre for both axes, followed by the unit (1 = metre)
+	pixelsPerMetre := uint32(math.Round(float64(dpi) / 0.0254))
+	chunk := make([]byte, 0, 4+4+9+4)
+	chunk = binary.BigEndian.AppendUint32(chunk, 9)
+	chunk = append(chunk, "pHYs"...)
+	chunk = binary.BigEndian.AppendUint32(chunk, pixelsPerMetre)
+	chunk = binary.BigEndian.AppendUint32(chunk, pixelsPerMetre)
+	chunk = append(chunk, 1)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	out := make([]byte, 0, len(data)+len(chunk))
+	out = append(out, data[:ihdrEnd]...)
+	out = append(out, chunk...)
+	out = append(out, data[ihdrEnd:]...)
+	return out, nil
+}
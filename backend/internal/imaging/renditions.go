@@ -0,0 +1,67 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// RenditionSizes are the longest-edge pixel sizes generated for every uploaded asset
+var RenditionSizes = []int{128, 512, 1024}
+
+// Rendition is a downscaled, PNG-encoded copy of an image
+type Rendition struct {
+	Size   int
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// GenerateRenditions downscales img so its longest edge matches each of the given
+// sizes. Sizes that are not smaller than the original are skipped, since upscaling
+// would only produce a larger file with no extra detail.
+func GenerateRenditions(img image.Image, sizes []int) ([]Rendition, error) {
+	bounds := img.Bounds()
+	longest := max(bounds.Dx(), bounds.Dy())
+
+	var renditions []Rendition
+	for _, size := range sizes {
+		if size <= 0 || size >= longest {
+			continue
+		}
+
+		resized := Resize(img, size)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, resized); err != nil {
+			return nil, fmt.Errorf("failed to encode %dpx rendition: %w", size, err)
+		}
+
+		renditions = append(renditions, Rendition{
+			Size:   size,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+			Data:   buf.Bytes(),
+		})
+	}
+
+	return renditions, nil
+}
+
+// Resize scales img so that its longest edge is size pixels, preserving aspect
+// ratio and the alpha channel
+func Resize(img image.Image, size int) *image.NRGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	return Scale(img, width, height)
+}
@@ -0,0 +1,80 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// MaxDimension is the largest width or height accepted for uploaded images.
+// It guards against decompression bombs that declare huge sizes in a small file.
+const MaxDimension = 8192
+
+// pngSignature is the 8-byte magic number every PNG file starts with
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// ErrNotPNG is returned when the content is not a PNG image
+var ErrNotPNG = errors.New("file content is not a PNG image")
+
+// PNGInfo describes a validated PNG image
+type PNGInfo struct {
+	Width    int
+	Height   int
+	HasAlpha bool
+}
+
+// IsPNG reports whether data starts with the PNG signature
+func IsPNG(data []byte) bool {
+	return bytes.HasPrefix(data, pngSignature)
+}
+
+// InspectPNG sniffs the magic bytes, validates the header and fully decodes the
+// image, so truncated or corrupt files are rejected. HasAlpha is only true when
+// at least one pixel is actually transparent, not merely when the color type
+// carries an alpha channel.
+func InspectPNG(data []byte) (image.Image, PNGInfo, error) {
+	if !IsPNG(data) {
+		return nil, PNGInfo{}, ErrNotPNG
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, PNGInfo{}, fmt.Errorf("invalid PNG header: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, PNGInfo{}, fmt.Errorf("invalid PNG dimensions %dx%d", cfg.Width, cfg.Height)
+	}
+	if cfg.Width > MaxDimension || cfg.Height > MaxDimension {
+		return nil, PNGInfo{}, fmt.Errorf("image dimensions %dx%d exceed the %dpx limit", cfg.Width, cfg.Height, MaxDimension)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, PNGInfo{}, fmt.Errorf("corrupt PNG data: %w", err)
+	}
+
+	return img, PNGInfo{
+		Width:    cfg.Width,
+		Height:   cfg.Height,
+		HasAlpha: HasTransparency(img),
+	}, nil
+}
+
+// HasTransparency reports whether any pixel in img is not fully opaque
+func HasTransparency(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return true
+			}
+		}
+	}
+	return false
+}
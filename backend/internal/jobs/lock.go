@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"scrapyuk-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DBLocker implements scheduler.Locker with leases in the job_locks table, so
+// only one process sharing the database runs a given job at a time
+type DBLocker struct {
+	db     *gorm.DB
+	holder string
+}
+
+// NewDBLocker creates a locker identifying this process by host, PID and a random suffix
+func NewDBLocker(db *gorm.DB) *DBLocker {
+	host, _ := os.Hostname()
+	return &DBLocker{
+		db:     db,
+		holder: fmt.Sprintf("%s/%d/%s", host, os.Getpid(), uuid.New().String()[:8]),
+	}
+}
+
+// TryLock takes the named lease if it is free or its previous holder's lease has expired
+func (l *DBLocker) TryLock(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	result := l.db.WithContext(ctx).Exec(
+		`INSERT INTO job_locks (name, holder, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+		WHERE job_locks.expires_at < ?`,
+		name, l.holder, now.Add(ttl), now,
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Unlock releases the named lease if this process still holds it
+func (l *DBLocker) Unlock(ctx context.Context, name string) error {
+	return l.db.WithContext(ctx).
+		Where("name = ? AND holder = ?", name, l.holder).
+		Delete(&models.JobLock{}).Error
+}
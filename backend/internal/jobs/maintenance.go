@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/handlers"
+	"scrapyuk-backend/internal/models"
+	"scrapyuk-backend/internal/scheduler"
+)
+
+// assetPrefix is the storage prefix under which project assets are stored
+const assetPrefix = "projects/"
+
+// Register adds the built-in maintenance jobs to s according to cfg
+func Register(s *scheduler.Scheduler, cfg config.JobsConfig) error {
+	sharedLinks := handlers.NewSharedLinkHandler()
+
+	jobs := []scheduler.Job{
+		{
+			Name:     "expired-link-cleanup",
+			Interval: cfg.LinkCleanupInterval,
+			Run: func(ctx context.Context) error {
+				return sharedLinks.CleanupExpiredLinks()
+			},
+		},
+		{
+			Name:     "orphaned-asset-sweep",
+			Interval: cfg.OrphanSweepInterval,
+			Run: func(ctx context.Context) error {
+				return SweepOrphanedAssets(ctx, cfg.OrphanGracePeriod)
+			},
+		},
+	}
+
+	// A retention of zero keeps every revision
+	if cfg.RevisionRetention > 0 {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "revision-pruning",
+			Interval: cfg.RevisionPruneInterval,
+			Run: func(ctx context.Context) error {
+				return PruneRevisions(ctx, cfg.RevisionRetention)
+			},
+		})
+	}
+
+	for _, job := range jobs {
+		job.Jitter = cfg.Jitter
+		if err := s.Register(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SweepOrphanedAssets deletes stored asset files that no asset or rendition
+// references. Files younger than gracePeriod are kept, since uploads store
+// their files before the asset row is created.
+func SweepOrphanedAssets(ctx context.Context, gracePeriod time.Duration) error {
+	if !config.IsStorageAvailable() {
+		return nil
+	}
+	store := config.GetStorage()
+	db := config.GetDB().WithContext(ctx)
+
+	objects, err := store.List(ctx, assetPrefix)
+	if err != nil {
+		return err
+	}
+
+	var assetPaths, renditionPaths []string
+	if err := db.Model(&models.Asset{}).Pluck("file_path", &assetPaths).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.AssetRendition{}).Pluck("file_path", &renditionPaths).Error; err != nil {
+		return err
+	}
+
+	referenced := make(map[string]bool, len(assetPaths)+len(renditionPaths))
+	for _, path := range append(assetPaths, renditionPaths...) {
+		referenced[strings.TrimPrefix(path, "/")] = true
+	}
+
+	cutoff := time.Now().Add(-gracePeriod)
+	deleted := 0
+	for _, object := range objects {
+		if referenced[object.Key] || object.LastModified.After(cutoff) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := store.Delete(ctx, object.Key); err != nil {
+			log.Printf("Failed to delete orphaned asset %s: %v", object.Key, err)
+			continue
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		log.Printf("Swept %d orphaned asset file(s)", deleted)
+	}
+	return nil
+}
+
+// PruneRevisions deletes all but the newest keep revisions of every project.
+// Revisions a buyer approved are always kept.
+func PruneRevisions(ctx context.Context, keep int) error {
+	result := config.GetDB().WithContext(ctx).Exec(
+		`DELETE FROM project_revisions
+		WHERE number <= (SELECT projects.version FROM projects WHERE projects.id = project_revisions.project_id) - ?
+		AND NOT EXISTS (
+			SELECT 1 FROM approval_decisions
+			WHERE approval_decisions.project_id = project_revisions.project_id
+			AND approval_decisions.revision = project_revisions.number
+			AND approval_decisions.decision = ?
+		)`,
+		keep, models.ApprovalApproved,
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Pruned %d old project revision(s)", result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// sameJSON reports whether two JSON texts encode the same value
+func sameJSON(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("result %s is not JSON: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("want %s is not JSON: %v", want, err)
+	}
+	return reflect.DeepEqual(g, w)
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add member", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{"add replaces member", `{"a":1}`, `[{"op":"add","path":"/a","value":[1]}]`, `{"a":[1]}`},
+		{"add inserts into array", `{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		{"add at array end", `{"a":[1]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2]}`},
+		{"add appends with dash", `{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		{"add to nested array", `{"a":{"b":[]}}`, `[{"op":"add","path":"/a/b/-","value":{"c":1}}]`, `{"a":{"b":[{"c":1}]}}`},
+		{"add null value", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`},
+		{"add whole document", `{"a":1}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{"remove member", `{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`},
+		{"remove array element", `{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1,3]}`},
+		{"replace member", `{"a":1}`, `[{"op":"replace","path":"/a","value":"x"}]`, `{"a":"x"}`},
+		{"replace array element", `[1,2,3]`, `[{"op":"replace","path":"/1","value":9}]`, `[1,9,3]`},
+		{"replace whole document", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+		{"move member", `{"a":{"b":1},"c":{}}`, `[{"op":"move","from":"/a/b","path":"/c/d"}]`, `{"a":{},"c":{"d":1}}`},
+		{"move within array", `[1,2,3]`, `[{"op":"move","from":"/0","path":"/2"}]`, `[2,3,1]`},
+		{"move to itself", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"a":{"b":1}}`},
+		{"copy member", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{"copy is independent", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"copy into array", `{"a":[1,2]}`, `[{"op":"copy","from":"/a/0","path":"/a/-"}]`, `{"a":[1,2,1]}`},
+		{"test passes", `{"a":[1,{"b":"c"}]}`, `[{"op":"test","path":"/a","value":[1,{"b":"c"}]}]`, `{"a":[1,{"b":"c"}]}`},
+		{"test number forms", `{"a":1}`, `[{"op":"test","path":"/a","value":1.0}]`, `{"a":1}`},
+		{"escaped slash", `{"a/b":1}`, `[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`},
+		{"escaped tilde", `{"m~n":1}`, `[{"op":"remove","path":"/m~0n"}]`, `{}`},
+		{"escape order", `{"~1":1}`, `[{"op":"add","path":"/~01","value":2}]`, `{"~1":2}`},
+		{"empty key", `{}`, `[{"op":"add","path":"/","value":1}]`, `{"":1}`},
+		{"empty patch", `{"a":1}`, `[]`, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if !sameJSON(t, got, tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+	}{
+		{"invalid patch", `{}`, `{"op":"add"}`},
+		{"invalid document", `{`, `[]`},
+		{"unknown operation", `{}`, `[{"op":"merge","path":"/a","value":1}]`},
+		{"pointer without slash", `{}`, `[{"op":"add","path":"a","value":1}]`},
+		{"add without value", `{}`, `[{"op":"add","path":"/a"}]`},
+		{"add to missing parent", `{}`, `[{"op":"add","path":"/a/b","value":1}]`},
+		{"add past array end", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":2}]`},
+		{"add to scalar", `{"a":1}`, `[{"op":"add","path":"/a/b","value":2}]`},
+		{"remove missing member", `{"a":1}`, `[{"op":"remove","path":"/b"}]`},
+		{"remove out of range", `{"a":[1]}`, `[{"op":"remove","path":"/a/1"}]`},
+		{"remove with dash", `{"a":[1]}`, `[{"op":"remove","path":"/a/-"}]`},
+		{"negative index", `{"a":[1]}`, `[{"op":"remove","path":"/a/-1"}]`},
+		{"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`},
+		{"non-numeric index", `{"a":[1]}`, `[{"op":"remove","path":"/a/x"}]`},
+		{"replace missing member", `{"a":1}`, `[{"op":"replace","path":"/b","value":2}]`},
+		{"replace out of range", `[1]`, `[{"op":"replace","path":"/1","value":2}]`},
+		{"replace with dash", `[1]`, `[{"op":"replace","path":"/-","value":2}]`},
+		{"move missing source", `{}`, `[{"op":"move","from":"/a","path":"/b"}]`},
+		{"move into own child", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`},
+		{"copy missing source", `{}`, `[{"op":"copy","from":"/a","path":"/b"}]`},
+		{"test missing member", `{}`, `[{"op":"test","path":"/a","value":1}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Apply([]byte(tt.doc), []byte(tt.patch)); err == nil {
+				t.Errorf("Apply succeeded with %s, want an error", got)
+			}
+		})
+	}
+}
+
+func TestApplyTestFailure(t *testing.T) {
+	_, err := Apply([]byte(`{"a":"1"}`), []byte(`[{"op":"test","path":"/a","value":1}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Errorf("err = %v, want ErrTestFailed", err)
+	}
+}
+
+// A failing operation discards the operations before it
+func TestApplyIsAllOrNothing(t *testing.T) {
+	doc := []byte(`{"a":[1,2],"b":{"c":1}}`)
+	original := string(doc)
+	patch := []byte(`[
+		{"op":"add","path":"/a/-","value":3},
+		{"op":"remove","path":"/b/c"},
+		{"op":"test","path":"/a/0","value":99}
+	]`)
+
+	got, err := Apply(doc, patch)
+	if !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("err = %v, want ErrTestFailed", err)
+	}
+	if got != nil {
+		t.Errorf("got %s, want no document", got)
+	}
+	if string(doc) != original {
+		t.Errorf("document changed to %s", doc)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Cases from RFC 7396 appendix A
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null deletes", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"null deletes one", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"null deletes nested", `{"a":{"b":"c","d":1}}`, `{"a":{"b":null}}`, `{"a":{"d":1}}`},
+		{"null on missing member", `{"a":1}`, `{"b":null}`, `{"a":1}`},
+		{"array replaced", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"value replaced by array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"arrays not merged", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"existing nulls kept", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{"object into scalar", `{"a":"foo"}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{"array patch replaces document", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"null patch replaces document", `{"a":"foo"}`, `null`, `null`},
+		{"string patch replaces document", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"object patch onto array", `["a","b"]`, `{"a":"b"}`, `{"a":"b"}`},
+		{"object patch onto scalar", `"x"`, `{"a":{"b":null}}`, `{"a":{}}`},
+		{"empty document", ``, `{"a":1}`, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch: %v", err)
+			}
+			if !sameJSON(t, got, tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatchErrors(t *testing.T) {
+	if _, err := MergePatch([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("invalid document: want an error")
+	}
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); err == nil {
+		t.Error("invalid patch: want an error")
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"scrapyuk-backend/config"
+	"scrapyuk-backend/internal/auth"
+	"scrapyuk-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys set by RequireAuth
+const (
+	ContextUserKey    = "user"
+	ContextSessionKey = "session"
+)
+
+// RequireAuth rejects requests without a valid, unrevoked creator session
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, session, reason := authenticate(c)
+		if user == nil {
+			abortUnauthorized(c, reason)
+			return
+		}
+
+		c.Set(ContextUserKey, user)
+		c.Set(ContextSessionKey, session)
+		c.Next()
+	}
+}
+
+// RequireAdmin rejects creators without the admin role. It must run after RequireAuth.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := c.Get(ContextUserKey)
+		if !ok || !user.(*models.User).IsAdmin() {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Message: "Access denied",
+				Error:   "Only administrators can perform this action",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// OptionalAuth identifies the creator when a valid session is presented but lets
+// anonymous requests through, for routes that also accept other credentials
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user, session, _ := authenticate(c); user != nil {
+			c.Set(ContextUserKey, user)
+			c.Set(ContextSessionKey, session)
+		}
+		c.Next()
+	}
+}
+
+// authenticate resolves the creator and session for the request's token. When
+// it fails, user is nil and reason explains why.
+func authenticate(c *gin.Context) (user *models.User, session *models.Session, reason string) {
+	token := extractToken(c)
+	if token == "" {
+		return nil, nil, "Authentication token is missing"
+	}
+
+	claims, err := auth.ParseToken(config.GetJWTSecret(), token)
+	if err != nil {
+		return nil, nil, err.Error()
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, nil, err.Error()
+	}
+
+	db := config.GetDB()
+
+	session = &models.Session{}
+	if err := db.Where("token_id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?",
+		claims.ID, userID, time.Now()).First(session).Error; err != nil {
+		return nil, nil, "Session has expired or been revoked"
+	}
+
+	user = &models.User{}
+	if err := db.First(user, userID).Error; err != nil {
+		return nil, nil, "User no longer exists"
+	}
+
+	return user, session, ""
+}
+
+// CurrentUser returns the authenticated creator set by RequireAuth
+func CurrentUser(c *gin.Context) *models.User {
+	if value, ok := c.Get(ContextUserKey); ok {
+		if user, ok := value.(*models.User); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+// CurrentSession returns the session set by RequireAuth
+func CurrentSession(c *gin.Context) *models.Session {
+	if value, ok := c.Get(ContextSessionKey); ok {
+		if session, ok := value.(*models.Session); ok {
+			return session
+		}
+	}
+	return nil
+}
+
+// extractToken reads the session token from the Authorization header or session cookie
+func extractToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if token, found := strings.CutPrefix(header, "Bearer "); found {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	if cookie, err := c.Cookie(config.SessionCookieName); err == nil {
+		return cookie
+	}
+
+	return ""
+}
+
+func abortUnauthorized(c *gin.Context, reason string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, models.APIResponse{
+		Success: false,
+		Message: "Authentication required",
+		Error:   reason,
+	})
+}
@@ -25,9 +25,9 @@ func SetupCORS() gin.HandlerFunc {
 
 	config := cors.Config{
 		AllowOrigins:     origins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "If-Match", "X-Viewer-Token"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Type", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
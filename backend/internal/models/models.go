@@ -1,26 +1,144 @@
 package models
 
 import (
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"log"
 	"time"
 
+	"scrapyuk-backend/internal/pricing"
+	"scrapyuk-backend/internal/scene"
+
 	"gorm.io/gorm"
 )
 
+// User represents a creator account
+type User struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Email        string    `gorm:"uniqueIndex;not null" json:"email"`
+	Name         string    `gorm:"not null" json:"name"`
+	PasswordHash string    `gorm:"not null" json:"-"`
+	Role         string    `gorm:"not null;default:creator" json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// User roles. Admins additionally manage shop-wide settings such as prices.
+const (
+	RoleCreator = "creator"
+	RoleAdmin   = "admin"
+)
+
+// IsAdmin reports whether the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// Session represents an issued login token that can be revoked on logout
+type Session struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenID   string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// FrameTemplate describes a physical frame product that projects are built for.
+// Dimensions are interior measurements in centimetres.
+type FrameTemplate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"uniqueIndex;not null" json:"name"`
+	WidthCM      float64   `gorm:"not null" json:"width_cm"`
+	HeightCM     float64   `gorm:"not null" json:"height_cm"`
+	DepthCM      float64   `gorm:"not null" json:"depth_cm"`
+	MaxLayers    int       `gorm:"not null" json:"max_layers"`
+	WallMaterial string    `json:"wall_material"`
+	LEDMounts    []Vector3 `gorm:"type:text;serializer:json" json:"led_mounts"` // in scene coordinates
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SizeLabel returns the template's dimensions as "<width>x<height>", the format
+// of Project.FrameSize
+func (t *FrameTemplate) SizeLabel() string {
+	return fmt.Sprintf("%gx%g", t.WidthCM, t.HeightCM)
+}
+
+// Contains reports whether a point in scene coordinates lies inside the frame
+func (t *FrameTemplate) Contains(v Vector3) bool {
+	return v.X >= -t.WidthCM/2 && v.X <= t.WidthCM/2 &&
+		v.Y >= -t.HeightCM/2 && v.Y <= t.HeightCM/2 &&
+		v.Z >= 0 && v.Z <= t.DepthCM
+}
+
+// Bounds returns the interior of the frame for scene validation
+func (t *FrameTemplate) Bounds() scene.Bounds {
+	return scene.Bounds{
+		Width:     t.WidthCM,
+		Height:    t.HeightCM,
+		Depth:     t.DepthCM,
+		MaxLayers: t.MaxLayers,
+	}
+}
+
 // Project represents a scrapbook project
 type Project struct {
-	ID          uint            `gorm:"primaryKey" json:"id"`
-	Title       string          `gorm:"not null" json:"title"`
-	FrameSize   string          `gorm:"not null" json:"frame_size"` // "20x20" or "20x30"
-	ProjectData json.RawMessage `gorm:"type:text" json:"project_data"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"-"`
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	OwnerID         uint            `gorm:"index" json:"owner_id"`
+	Title           string          `gorm:"not null" json:"title"`
+	FrameTemplateID *uint           `gorm:"index" json:"frame_template_id"`
+	FrameSize       string          `gorm:"not null" json:"frame_size"` // "<width>x<height>" of the frame template
+	ProjectData     json.RawMessage `gorm:"type:text" json:"project_data"`
+	IsTemplate      bool            `gorm:"not null;default:false;index" json:"is_template"` // starter design new projects can be created from
+	Version         int             `gorm:"not null;default:1" json:"version"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt  `gorm:"index" json:"-"`
+
+	// Relationships
+	FrameTemplate *FrameTemplate `gorm:"foreignKey:FrameTemplateID;constraint:OnDelete:RESTRICT" json:"frame_template,omitempty"`
+	Assets        []Asset        `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"assets,omitempty"`
+	Objects       []Object       `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"objects,omitempty"`
+	SharedLinks   []SharedLink   `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"shared_links,omitempty"`
+}
+
+// AfterFind upgrades project data saved with an older scene schema version so
+// callers always see the current format. Documents that cannot be upgraded are
+// returned unchanged and logged.
+func (p *Project) AfterFind(tx *gorm.DB) error {
+	upgraded, err := scene.Upgrade(p.ProjectData)
+	if err != nil {
+		log.Printf("Project %d: cannot upgrade project data: %v", p.ID, err)
+		return nil
+	}
+	p.ProjectData = upgraded
+	return nil
+}
+
+// ProjectRevision is an immutable snapshot of a project taken on every save.
+// Number matches the project version the snapshot was saved as.
+type ProjectRevision struct {
+	ID              uint             `gorm:"primaryKey" json:"id"`
+	ProjectID       uint             `gorm:"not null;uniqueIndex:idx_project_revision_number" json:"project_id"`
+	Number          int              `gorm:"not null;uniqueIndex:idx_project_revision_number" json:"number"`
+	AuthorID        uint             `gorm:"index" json:"author_id"`
+	Action          string           `gorm:"not null" json:"action"` // "create", "update", "patch", "objects", "restore" or "baseline"
+	RestoredFrom    *int             `json:"restored_from,omitempty"`
+	Title           string           `gorm:"not null" json:"title"`
+	FrameTemplateID *uint            `json:"frame_template_id"`
+	FrameSize       string           `gorm:"not null" json:"frame_size"`
+	ProjectData     json.RawMessage  `gorm:"type:text" json:"project_data,omitempty"`
+	Objects         []ObjectSnapshot `gorm:"type:text;serializer:json" json:"objects,omitempty"` // nil in revisions saved before objects were recorded
+	CreatedAt       time.Time        `json:"created_at"`
 
 	// Relationships
-	Assets      []Asset      `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"assets,omitempty"`
-	Objects     []Object     `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"objects,omitempty"`
-	SharedLinks []SharedLink `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"shared_links,omitempty"`
+	Project Project `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"-"`
+	Author  *User   `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
 }
 
 // Asset represents an uploaded image asset
@@ -29,11 +147,79 @@ type Asset struct {
 	ProjectID  uint      `gorm:"not null;index" json:"project_id"`
 	Filename   string    `gorm:"not null" json:"filename"`
 	FilePath   string    `gorm:"not null" json:"file_path"`
+	Size       int64     `json:"size"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	HasAlpha   bool      `json:"has_alpha"`
 	UploadedAt time.Time `json:"uploaded_at"`
 
+	// Computed fields
+	URL          string   `gorm:"-" json:"url"`
+	ThumbnailURL string   `gorm:"-" json:"thumbnail_url"`
+	Warnings     []string `gorm:"-" json:"warnings,omitempty"`
+
 	// Relationships
-	Project Project  `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
-	Objects []Object `gorm:"foreignKey:AssetID;constraint:OnDelete:SET NULL" json:"objects,omitempty"`
+	Project    Project          `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+	Objects    []Object         `gorm:"foreignKey:AssetID;constraint:OnDelete:SET NULL" json:"objects,omitempty"`
+	Renditions []AssetRendition `gorm:"foreignKey:AssetID;constraint:OnDelete:CASCADE" json:"renditions,omitempty"`
+}
+
+// AssetRendition represents a downscaled copy of an asset, sized by its longest edge
+type AssetRendition struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	AssetID   uint      `gorm:"not null;index" json:"asset_id"`
+	Size      int       `gorm:"not null" json:"size"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	FilePath  string    `gorm:"not null" json:"file_path"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Computed fields
+	URL string `gorm:"-" json:"url"`
+}
+
+// AssetURL returns the API path serving the stored object with the given key
+func AssetURL(key string) string {
+	return "/api/assets/" + key
+}
+
+// AfterFind populates computed URL fields
+func (a *Asset) AfterFind(tx *gorm.DB) error {
+	a.populateURLs()
+	return nil
+}
+
+// AfterCreate populates computed URL fields
+func (a *Asset) AfterCreate(tx *gorm.DB) error {
+	a.populateURLs()
+	return nil
+}
+
+// populateURLs sets the original and thumbnail URLs, using the smallest
+// rendition as the thumbnail when one exists
+func (a *Asset) populateURLs() {
+	a.URL = AssetURL(a.FilePath)
+	a.ThumbnailURL = a.URL
+
+	smallest := 0
+	for _, rendition := range a.Renditions {
+		if smallest == 0 || rendition.Size < smallest {
+			smallest = rendition.Size
+			a.ThumbnailURL = AssetURL(rendition.FilePath)
+		}
+	}
+}
+
+// AfterFind populates computed URL fields
+func (r *AssetRendition) AfterFind(tx *gorm.DB) error {
+	r.URL = AssetURL(r.FilePath)
+	return nil
+}
+
+// AfterCreate populates computed URL fields
+func (r *AssetRendition) AfterCreate(tx *gorm.DB) error {
+	r.URL = AssetURL(r.FilePath)
+	return nil
 }
 
 // Object represents a 3D object in the scene
@@ -44,13 +230,54 @@ type Object struct {
 	Position   json.RawMessage `gorm:"type:text;not null" json:"position"`
 	Layers     int             `gorm:"default:1" json:"layers"`
 	Properties json.RawMessage `gorm:"type:text" json:"properties"`
+	SortOrder  int             `gorm:"default:0;index" json:"sort_order"`
 	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
 
 	// Relationships
 	Project Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
 	Asset   *Asset  `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
 }
 
+// ObjectSnapshot is an Object row as recorded in a project revision
+type ObjectSnapshot struct {
+	ID         uint            `json:"id"`
+	AssetID    *uint           `json:"asset_id"`
+	Position   json.RawMessage `json:"position"`
+	Layers     int             `json:"layers"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+	SortOrder  int             `json:"sort_order"`
+}
+
+// SnapshotObjects records a project's Object rows for a revision
+func SnapshotObjects(objects []Object) []ObjectSnapshot {
+	snapshots := make([]ObjectSnapshot, len(objects))
+	for i, object := range objects {
+		snapshots[i] = ObjectSnapshot{
+			ID:         object.ID,
+			AssetID:    object.AssetID,
+			Position:   object.Position,
+			Layers:     object.Layers,
+			Properties: object.Properties,
+			SortOrder:  object.SortOrder,
+		}
+	}
+	return snapshots
+}
+
+// Object returns the recorded row for the given project
+func (s *ObjectSnapshot) Object(projectID uint) Object {
+	return Object{
+		ID:         s.ID,
+		ProjectID:  projectID,
+		AssetID:    s.AssetID,
+		Position:   s.Position,
+		Layers:     s.Layers,
+		Properties: s.Properties,
+		SortOrder:  s.SortOrder,
+	}
+}
+
 // SharedLink represents a shareable link for a project
 type SharedLink struct {
 	ID        uint       `gorm:"primaryKey" json:"id"`
@@ -59,19 +286,203 @@ type SharedLink struct {
 	ExpiresAt *time.Time `json:"expires_at"`
 	CreatedAt time.Time  `json:"created_at"`
 
+	// Access restrictions
+	PasswordHash        string     `json:"-"`
+	MaxViews            *int       `json:"max_views"`
+	ViewCount           int        `gorm:"not null;default:0" json:"view_count"`
+	AllowedCIDRs        StringList `gorm:"type:text" json:"allowed_cidrs"`
+	AllowedEmailDomains StringList `gorm:"type:text" json:"allowed_email_domains"`
+
+	// Analytics
+	LastViewedAt *time.Time `json:"last_viewed_at"`
+
+	// Buyer sign-off
+	ApprovalStatus   string     `gorm:"not null;default:pending" json:"approval_status"`
+	ApprovedRevision *int       `json:"approved_revision"`
+	DecidedAt        *time.Time `json:"decided_at"`
+	DecidedBy        string     `json:"decided_by,omitempty"`
+
+	// Whether buyers see the project's price quote
+	ShowQuote bool `gorm:"not null;default:false" json:"show_quote"`
+
+	// Computed fields
+	HasPassword bool `gorm:"-" json:"has_password"`
+
 	// Relationships
 	Project Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
 }
 
+// AfterFind populates computed fields
+func (l *SharedLink) AfterFind(tx *gorm.DB) error {
+	l.HasPassword = l.PasswordHash != ""
+	return nil
+}
+
+// AfterCreate populates computed fields
+func (l *SharedLink) AfterCreate(tx *gorm.DB) error {
+	l.HasPassword = l.PasswordHash != ""
+	return nil
+}
+
+// RequiresUnlock reports whether viewers must unlock the link before viewing it
+func (l *SharedLink) RequiresUnlock() bool {
+	return l.PasswordHash != "" || len(l.AllowedEmailDomains) > 0
+}
+
+// Shared link approval states. A link starts pending; the buyer may request
+// changes any number of times and approval is final.
+const (
+	ApprovalPending          = "pending"
+	ApprovalChangesRequested = "changes_requested"
+	ApprovalApproved         = "approved"
+)
+
+// ApprovalDecision is the durable record of a buyer's decision on a shared
+// project. It outlives the shared link so sign-offs are never lost.
+type ApprovalDecision struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ProjectID    uint      `gorm:"not null;index" json:"project_id"`
+	SharedLinkID *uint     `gorm:"index" json:"shared_link_id,omitempty"`
+	Decision     string    `gorm:"not null" json:"decision"` // "approved" or "changes_requested"
+	Revision     int       `gorm:"not null" json:"revision"`
+	Name         string    `gorm:"not null" json:"name"`
+	Note         string    `gorm:"type:text" json:"note,omitempty"`
+	IPHash       string    `json:"ip_hash"`
+	UserAgent    string    `json:"user_agent"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relationships
+	Project    Project     `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"-"`
+	SharedLink *SharedLink `gorm:"foreignKey:SharedLinkID;constraint:OnDelete:SET NULL" json:"-"`
+}
+
+// Shared link access outcomes
+const (
+	AccessOutcomeSuccess = "success"
+	AccessOutcomeExpired = "expired"
+	AccessOutcomeDenied  = "denied"
+)
+
+// SharedLinkAccess is an audit record of a single attempt to open a shared link.
+// Viewer IPs are stored as keyed hashes so unique viewers can be counted
+// without keeping the addresses themselves.
+type SharedLinkAccess struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SharedLinkID uint      `gorm:"not null;index:idx_shared_link_access_time" json:"shared_link_id"`
+	IPHash       string    `gorm:"not null" json:"ip_hash"`
+	UserAgent    string    `json:"user_agent"`
+	Referrer     string    `json:"referrer"`
+	Outcome      string    `gorm:"not null" json:"outcome"` // "success", "expired" or "denied"
+	Reason       string    `json:"reason,omitempty"`        // e.g. "view_limit", "network", "locked", "password", "throttled"
+	CreatedAt    time.Time `gorm:"index:idx_shared_link_access_time" json:"created_at"`
+
+	// Relationships
+	SharedLink SharedLink `gorm:"foreignKey:SharedLinkID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Comment is a buyer's feedback on a shared project, or a reply within a
+// feedback thread. Top-level comments may be pinned to a scene object or a
+// point in the scene.
+type Comment struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ProjectID    uint       `gorm:"not null;index" json:"project_id"`
+	SharedLinkID *uint      `gorm:"index" json:"shared_link_id,omitempty"` // link the buyer commented through
+	ParentID     *uint      `gorm:"index" json:"parent_id,omitempty"`      // set on replies
+	AuthorID     *uint      `json:"author_id,omitempty"`                   // set when a creator wrote the comment
+	AuthorName   string     `gorm:"not null" json:"author_name"`
+	Message      string     `gorm:"type:text;not null" json:"message"`
+	ObjectID     string     `json:"object_id,omitempty"` // scene object ID
+	Position     *Vector3   `gorm:"serializer:json" json:"position,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at"`
+	ResolvedByID *uint      `json:"resolved_by_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	// Relationships
+	Project    Project     `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"-"`
+	SharedLink *SharedLink `gorm:"foreignKey:SharedLinkID;constraint:OnDelete:SET NULL" json:"-"`
+	Replies    []Comment   `gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE" json:"replies,omitempty"`
+}
+
+// PriceRate is the unit price of one line item of a project quote
+type PriceRate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Key       string    `gorm:"uniqueIndex;not null" json:"key"`
+	Name      string    `gorm:"not null" json:"name"`
+	Unit      string    `gorm:"not null" json:"unit"`
+	UnitPrice float64   `gorm:"not null" json:"unit_price"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobLock is a lease held by the process currently running a background job
+type JobLock struct {
+	Name      string    `gorm:"primaryKey" json:"name"`
+	Holder    string    `gorm:"not null" json:"holder"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+}
+
+// StringList is a list of strings stored as a JSON array in a text column
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
 // TableName methods for custom table names (optional)
+func (User) TableName() string {
+	return "users"
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+func (FrameTemplate) TableName() string {
+	return "frame_templates"
+}
+
 func (Project) TableName() string {
 	return "projects"
 }
 
+func (ProjectRevision) TableName() string {
+	return "project_revisions"
+}
+
 func (Asset) TableName() string {
 	return "assets"
 }
 
+func (AssetRendition) TableName() string {
+	return "asset_renditions"
+}
+
 func (Object) TableName() string {
 	return "objects"
 }
@@ -80,23 +491,237 @@ func (SharedLink) TableName() string {
 	return "shared_links"
 }
 
-// ProjectCreateRequest represents the request payload for creating a project
+func (SharedLinkAccess) TableName() string {
+	return "shared_link_accesses"
+}
+
+func (Comment) TableName() string {
+	return "comments"
+}
+
+func (ApprovalDecision) TableName() string {
+	return "approval_decisions"
+}
+
+func (PriceRate) TableName() string {
+	return "price_rates"
+}
+
+func (JobLock) TableName() string {
+	return "job_locks"
+}
+
+// LoginRequest represents the request payload for creator login
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse represents the payload returned after a successful login
+type LoginResponse struct {
+	User      User      `json:"user"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ProjectCreateRequest represents the request payload for creating a project.
+// The frame is chosen by frame_template_id; frame_size ("<width>x<height>") is
+// still accepted and selects the first template with those dimensions.
+// Projects created from a starter design (template_id) copy its scene, objects
+// and assets, and use its frame unless another one is given.
 type ProjectCreateRequest struct {
-	Title       string          `json:"title" binding:"required"`
-	FrameSize   string          `json:"frame_size" binding:"required,oneof=20x20 20x30"`
-	ProjectData json.RawMessage `json:"project_data"`
+	Title           string          `json:"title" binding:"required"`
+	FrameTemplateID *uint           `json:"frame_template_id" binding:"required_without_all=FrameSize TemplateID"`
+	FrameSize       *string         `json:"frame_size"`
+	TemplateID      *uint           `json:"template_id"`
+	ProjectData     json.RawMessage `json:"project_data"`
+}
+
+// ProjectCopyRequest represents the optional request payload for duplicating a
+// project or saving it as a starter design
+type ProjectCopyRequest struct {
+	Title *string `json:"title" binding:"omitempty,min=1,max=200"`
 }
 
 // ProjectUpdateRequest represents the request payload for updating a project
 type ProjectUpdateRequest struct {
-	Title       *string         `json:"title"`
-	FrameSize   *string         `json:"frame_size" binding:"omitempty,oneof=20x20 20x30"`
-	ProjectData json.RawMessage `json:"project_data"`
+	Title           *string         `json:"title"`
+	FrameTemplateID *uint           `json:"frame_template_id"`
+	FrameSize       *string         `json:"frame_size"`
+	ProjectData     json.RawMessage `json:"project_data"`
+}
+
+// FrameTemplateCreateRequest represents the request payload for creating a frame template
+type FrameTemplateCreateRequest struct {
+	Name         string    `json:"name" binding:"required,max=100"`
+	WidthCM      float64   `json:"width_cm" binding:"required,gt=0,lte=200"`
+	HeightCM     float64   `json:"height_cm" binding:"required,gt=0,lte=200"`
+	DepthCM      float64   `json:"depth_cm" binding:"required,gt=0,lte=50"`
+	MaxLayers    int       `json:"max_layers" binding:"required,min=1,max=10"`
+	WallMaterial string    `json:"wall_material" binding:"max=100"`
+	LEDMounts    []Vector3 `json:"led_mounts"`
+}
+
+// FrameTemplateUpdateRequest represents the request payload for updating a frame template
+type FrameTemplateUpdateRequest struct {
+	Name         *string    `json:"name" binding:"omitempty,max=100"`
+	WidthCM      *float64   `json:"width_cm" binding:"omitempty,gt=0,lte=200"`
+	HeightCM     *float64   `json:"height_cm" binding:"omitempty,gt=0,lte=200"`
+	DepthCM      *float64   `json:"depth_cm" binding:"omitempty,gt=0,lte=50"`
+	MaxLayers    *int       `json:"max_layers" binding:"omitempty,min=1,max=10"`
+	WallMaterial *string    `json:"wall_material" binding:"omitempty,max=100"`
+	LEDMounts    *[]Vector3 `json:"led_mounts"`
+}
+
+// Vector3 represents a point in scene coordinates
+type Vector3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// ObjectCreateRequest represents the request payload for creating a scene object
+type ObjectCreateRequest struct {
+	AssetID    *uint           `json:"asset_id"`
+	Position   *Vector3        `json:"position" binding:"required"`
+	Layers     *int            `json:"layers" binding:"omitempty,min=1,max=10"`
+	Properties json.RawMessage `json:"properties"`
+	SortOrder  *int            `json:"sort_order"`
+}
+
+// ObjectUpdateRequest represents the request payload for updating a scene object
+type ObjectUpdateRequest struct {
+	AssetID    *uint           `json:"asset_id"`
+	Position   *Vector3        `json:"position"`
+	Layers     *int            `json:"layers" binding:"omitempty,min=1,max=10"`
+	Properties json.RawMessage `json:"properties"`
+	SortOrder  *int            `json:"sort_order"`
+}
+
+// ObjectBatchUpdateItem represents a single object update within a batch
+type ObjectBatchUpdateItem struct {
+	ID uint `json:"id" binding:"required"`
+	ObjectUpdateRequest
+}
+
+// ObjectBatchUpdateRequest represents the request payload for updating several objects at once
+type ObjectBatchUpdateRequest struct {
+	Objects []ObjectBatchUpdateItem `json:"objects" binding:"required,min=1,dive"`
+}
+
+// ObjectReorderRequest represents the request payload for reordering a project's objects
+type ObjectReorderRequest struct {
+	ObjectIDs []uint `json:"object_ids" binding:"required,min=1"`
+}
+
+// PriceRateUpdateRequest represents the request payload for changing a quote price
+type PriceRateUpdateRequest struct {
+	Name      *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	UnitPrice *float64 `json:"unit_price" binding:"required,gte=0"`
 }
 
 // SharedLinkCreateRequest represents the request payload for creating a shared link
 type SharedLinkCreateRequest struct {
-	ExpiresAt *time.Time `json:"expires_at"`
+	ExpiresAt           *time.Time `json:"expires_at"`
+	Password            *string    `json:"password" binding:"omitempty,min=4,max=72"`
+	MaxViews            *int       `json:"max_views" binding:"omitempty,min=1"`
+	AllowedCIDRs        []string   `json:"allowed_cidrs"`
+	AllowedEmailDomains []string   `json:"allowed_email_domains"`
+	ShowQuote           bool       `json:"show_quote"`
+}
+
+// SharedLinkUnlockRequest represents the request payload for unlocking a restricted shared link
+type SharedLinkUnlockRequest struct {
+	Password string `json:"password"`
+	Email    string `json:"email" binding:"omitempty,email"`
+}
+
+// BuyerProject is the read-only project view served through a shared link.
+// Database IDs, storage keys and creator metadata are left out, and scene
+// object and asset references are replaced with opaque per-link identifiers.
+type BuyerProject struct {
+	Title     string         `json:"title"`
+	FrameSize string         `json:"frame_size"`
+	Frame     *BuyerFrame    `json:"frame,omitempty"`
+	Revision  int            `json:"revision"`
+	Scene     scene.Document `json:"scene"`
+	Assets    []BuyerAsset   `json:"assets"`
+	Quote     *pricing.Quote `json:"quote,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// BuyerFrame describes the physical frame of a shared project, in centimetres
+type BuyerFrame struct {
+	Name         string    `json:"name"`
+	WidthCM      float64   `json:"width_cm"`
+	HeightCM     float64   `json:"height_cm"`
+	DepthCM      float64   `json:"depth_cm"`
+	WallMaterial string    `json:"wall_material,omitempty"`
+	LEDMounts    []Vector3 `json:"led_mounts"`
+}
+
+// BuyerAsset is an image placed in a shared project, served through token-scoped URLs
+type BuyerAsset struct {
+	ID           string           `json:"id"`
+	Width        int              `json:"width"`
+	Height       int              `json:"height"`
+	HasAlpha     bool             `json:"has_alpha"`
+	URL          string           `json:"url"`
+	ThumbnailURL string           `json:"thumbnail_url"`
+	Renditions   []BuyerRendition `json:"renditions,omitempty"`
+}
+
+// BuyerRendition is a downscaled copy of a BuyerAsset
+type BuyerRendition struct {
+	Size   int    `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// BuyerComment is a comment thread entry as shown to shared link viewers
+type BuyerComment struct {
+	ID         string         `json:"id"`
+	AuthorName string         `json:"author_name"`
+	IsCreator  bool           `json:"is_creator"`
+	Message    string         `json:"message"`
+	ObjectID   string         `json:"object_id,omitempty"`
+	Position   *Vector3       `json:"position,omitempty"`
+	Resolved   bool           `json:"resolved"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Replies    []BuyerComment `json:"replies,omitempty"`
+}
+
+// BuyerLink describes the shared link a buyer is viewing
+type BuyerLink struct {
+	ExpiresAt        *time.Time `json:"expires_at"`
+	ViewsRemaining   *int       `json:"views_remaining,omitempty"`
+	ApprovalStatus   string     `json:"approval_status"`
+	ApprovedRevision *int       `json:"approved_revision,omitempty"`
+	DecidedAt        *time.Time `json:"decided_at,omitempty"`
+}
+
+// SharedLinkDecisionRequest represents the request payload for a buyer's approval decision.
+// Revision is the project revision the buyer reviewed, as shown in the buyer view.
+type SharedLinkDecisionRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approved changes_requested"`
+	Name     string `json:"name" binding:"required,max=100"`
+	Note     string `json:"note" binding:"max=2000"`
+	Revision int    `json:"revision" binding:"required,min=1"`
+}
+
+// CommentCreateRequest represents the request payload for a buyer comment on a shared project
+type CommentCreateRequest struct {
+	Name     string   `json:"name" binding:"required,max=100"`
+	Message  string   `json:"message" binding:"required,max=2000"`
+	ObjectID string   `json:"object_id"`
+	Position *Vector3 `json:"position"`
+	ReplyTo  string   `json:"reply_to"`
+}
+
+// CommentReplyRequest represents the request payload for a creator reply to a comment
+type CommentReplyRequest struct {
+	Message string `json:"message" binding:"required,max=2000"`
 }
 
 // APIResponse represents a standard API response
@@ -105,6 +730,7 @@ type APIResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 // PaginatedResponse represents a paginated API response
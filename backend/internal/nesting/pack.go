@@ -0,0 +1,289 @@
+// Package nesting lays the cut-out paper layers of a project onto printable
+// sheets, so they can be printed on as few sheets of cardstock as possible.
+//
+// Sheet coordinates are in centimetres from the top-left corner of the sheet,
+// with y running down the page as in print layouts.
+package nesting
+
+import (
+	"math"
+	"sort"
+)
+
+// Sheet is a paper size
+type Sheet struct {
+	Name     string  `json:"name"`
+	WidthCM  float64 `json:"width_cm"`
+	HeightCM float64 `json:"height_cm"`
+}
+
+// Sizes are the standard sheet sizes, by their query name
+var Sizes = map[string]Sheet{
+	"a4":     {Name: "A4", WidthCM: 21, HeightCM: 29.7},
+	"a3":     {Name: "A3", WidthCM: 29.7, HeightCM: 42},
+	"letter": {Name: "Letter", WidthCM: 21.59, HeightCM: 27.94},
+}
+
+// Options control how pieces are laid out. MarginCM is left blank on every
+// edge of the sheet; BleedCM is added around every piece so it can be cut
+// without white edges.
+type Options struct {
+	Sheet         Sheet
+	MarginCM      float64
+	BleedCM       float64
+	AllowRotation bool
+}
+
+// Piece is a layer to be laid out, identified by its cutting sheet order
+type Piece struct {
+	Order    int
+	File     string
+	ObjectID string
+	Layer    int
+	WidthCM  float64
+	HeightCM float64
+}
+
+// Placement is a piece's position on a sheet. X, Y, width and height describe
+// the trim box as placed, after any rotation; the bleed extends around it.
+type Placement struct {
+	Order    int     `json:"order"`
+	File     string  `json:"file"`
+	ObjectID string  `json:"object_id"`
+	Layer    int     `json:"layer"`
+	XCM      float64 `json:"x_cm"`
+	YCM      float64 `json:"y_cm"`
+	WidthCM  float64 `json:"width_cm"`
+	HeightCM float64 `json:"height_cm"`
+	Rotated  bool    `json:"rotated"`
+}
+
+// SheetLayout is one printed sheet
+type SheetLayout struct {
+	Number      int         `json:"number"`
+	Placements  []Placement `json:"placements"`
+	Utilisation float64     `json:"utilisation"`
+}
+
+// Unplaced is a piece too large for the sheet
+type Unplaced struct {
+	Order    int     `json:"order"`
+	ObjectID string  `json:"object_id"`
+	Layer    int     `json:"layer"`
+	WidthCM  float64 `json:"width_cm"`
+	HeightCM float64 `json:"height_cm"`
+}
+
+// Layout is the result of nesting a set of pieces
+type Layout struct {
+	Sheet       Sheet         `json:"sheet"`
+	MarginCM    float64       `json:"margin_cm"`
+	BleedCM     float64       `json:"bleed_cm"`
+	SheetCount  int           `json:"sheet_count"`
+	Utilisation float64       `json:"utilisation"`
+	Sheets      []SheetLayout `json:"sheets"`
+	Unplaced    []Unplaced    `json:"unplaced"`
+}
+
+// rect is an axis-aligned area of a sheet
+type rect struct {
+	x, y, w, h float64
+}
+
+// epsilon absorbs rounding when comparing lengths in cm
+const epsilon = 1e-9
+
+// Pack lays pieces out on as few sheets as it can. Pieces are placed largest
+// first using the maximal rectangles method with best short side fit, filling
+// one sheet before starting the next.
+func Pack(pieces []Piece, opts Options) *Layout {
+	layout := &Layout{
+		Sheet:    opts.Sheet,
+		MarginCM: opts.MarginCM,
+		BleedCM:  opts.BleedCM,
+		Sheets:   []SheetLayout{},
+		Unplaced: []Unplaced{},
+	}
+
+	usable := rect{
+		x: opts.MarginCM,
+		y: opts.MarginCM,
+		w: opts.Sheet.WidthCM - 2*opts.MarginCM,
+		h: opts.Sheet.HeightCM - 2*opts.MarginCM,
+	}
+
+	var remaining []Piece
+	for _, piece := range pieces {
+		w, h := piece.WidthCM+2*opts.BleedCM, piece.HeightCM+2*opts.BleedCM
+		fits := w <= usable.w+epsilon && h <= usable.h+epsilon
+		if opts.AllowRotation {
+			fits = fits || (h <= usable.w+epsilon && w <= usable.h+epsilon)
+		}
+		if !fits {
+			layout.Unplaced = append(layout.Unplaced, Unplaced{
+				Order:    piece.Order,
+				ObjectID: piece.ObjectID,
+				Layer:    piece.Layer,
+				WidthCM:  piece.WidthCM,
+				HeightCM: piece.HeightCM,
+			})
+			continue
+		}
+		remaining = append(remaining, piece)
+	}
+
+	sort.SliceStable(remaining, func(a, b int) bool {
+		longA := math.Max(remaining[a].WidthCM, remaining[a].HeightCM)
+		longB := math.Max(remaining[b].WidthCM, remaining[b].HeightCM)
+		if longA != longB {
+			return longA > longB
+		}
+		return remaining[a].WidthCM*remaining[a].HeightCM > remaining[b].WidthCM*remaining[b].HeightCM
+	})
+
+	var usedArea float64
+	for len(remaining) > 0 {
+		sheet := SheetLayout{Number: len(layout.Sheets) + 1, Placements: []Placement{}}
+		free := []rect{usable}
+
+		var next []Piece
+		for _, piece := range remaining {
+			placed, ok := place(&free, piece, opts)
+			if !ok {
+				next = append(next, piece)
+				continue
+			}
+			sheet.Placements = append(sheet.Placements, placed)
+		}
+
+		var area float64
+		for _, p := range sheet.Placements {
+			area += p.WidthCM * p.HeightCM
+		}
+		usedArea += area
+		sheet.Utilisation = ratio(area, opts.Sheet.WidthCM*opts.Sheet.HeightCM)
+		sort.Slice(sheet.Placements, func(a, b int) bool {
+			return sheet.Placements[a].Order < sheet.Placements[b].Order
+		})
+
+		layout.Sheets = append(layout.Sheets, sheet)
+		remaining = next
+	}
+
+	layout.SheetCount = len(layout.Sheets)
+	layout.Utilisation = ratio(usedArea, float64(layout.SheetCount)*opts.Sheet.WidthCM*opts.Sheet.HeightCM)
+	return layout
+}
+
+// place finds the free rectangle that fits the piece with the least leftover
+// on its shorter side, claims the space and returns the placement
+func place(free *[]rect, piece Piece, opts Options) (Placement, bool) {
+	w, h := piece.WidthCM+2*opts.BleedCM, piece.HeightCM+2*opts.BleedCM
+
+	best, bestShort, bestLong := -1, math.Inf(1), math.Inf(1)
+	rotated := false
+	try := func(i int, fr rect, w, h float64, rotate bool) {
+		if w > fr.w+epsilon || h > fr.h+epsilon {
+			return
+		}
+		short := math.Min(fr.w-w, fr.h-h)
+		long := math.Max(fr.w-w, fr.h-h)
+		if short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong, rotated = i, short, long, rotate
+		}
+	}
+	for i, fr := range *free {
+		try(i, fr, w, h, false)
+		if opts.AllowRotation && w != h {
+			try(i, fr, h, w, true)
+		}
+	}
+	if best < 0 {
+		return Placement{}, false
+	}
+
+	if rotated {
+		w, h = h, w
+	}
+	used := rect{x: (*free)[best].x, y: (*free)[best].y, w: w, h: h}
+	split(free, used)
+
+	placement := Placement{
+		Order:    piece.Order,
+		File:     piece.File,
+		ObjectID: piece.ObjectID,
+		Layer:    piece.Layer,
+		XCM:      round(used.x + opts.BleedCM),
+		YCM:      round(used.y + opts.BleedCM),
+		WidthCM:  piece.WidthCM,
+		HeightCM: piece.HeightCM,
+		Rotated:  rotated,
+	}
+	if rotated {
+		placement.WidthCM, placement.HeightCM = piece.HeightCM, piece.WidthCM
+	}
+	return placement, true
+}
+
+// split removes used from the free rectangles, keeping the maximal free
+// rectangles around it and dropping any contained in another
+func split(free *[]rect, used rect) {
+	var result []rect
+	for _, fr := range *free {
+		if used.x >= fr.x+fr.w-epsilon || used.x+used.w <= fr.x+epsilon ||
+			used.y >= fr.y+fr.h-epsilon || used.y+used.h <= fr.y+epsilon {
+			result = append(result, fr)
+			continue
+		}
+		if used.x > fr.x+epsilon {
+			result = append(result, rect{fr.x, fr.y, used.x - fr.x, fr.h})
+		}
+		if used.x+used.w < fr.x+fr.w-epsilon {
+			result = append(result, rect{used.x + used.w, fr.y, fr.x + fr.w - used.x - used.w, fr.h})
+		}
+		if used.y > fr.y+epsilon {
+			result = append(result, rect{fr.x, fr.y, fr.w, used.y - fr.y})
+		}
+		if used.y+used.h < fr.y+fr.h-epsilon {
+			result = append(result, rect{fr.x, used.y + used.h, fr.w, fr.y + fr.h - used.y - used.h})
+		}
+	}
+
+	pruned := make([]rect, 0, len(result))
+	for i, a := range result {
+		contained := false
+		for j, b := range result {
+			if i == j || !contains(b, a) {
+				continue
+			}
+			// Of two identical rectangles keep the first
+			if !contains(a, b) || j < i {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			pruned = append(pruned, a)
+		}
+	}
+	*free = pruned
+}
+
+// contains reports whether a lies entirely inside b
+func contains(b, a rect) bool {
+	return a.x >= b.x-epsilon && a.y >= b.y-epsilon &&
+		a.x+a.w <= b.x+b.w+epsilon && a.y+a.h <= b.y+b.h+epsilon
+}
+
+// ratio returns part/whole rounded to three decimals, or 0 for an empty whole
+func ratio(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(part/whole*1000) / 1000
+}
+
+// round rounds a length in cm to a tenth of a millimetre
+func round(cm float64) float64 {
+	return math.Round(cm*100) / 100
+}
@@ -0,0 +1,99 @@
+package nesting
+
+import (
+	"math"
+	"testing"
+)
+
+var testSheet = Sheet{Name: "Test", WidthCM: 20, HeightCM: 30}
+
+func piece(order int, w, h float64) Piece {
+	return Piece{Order: order, ObjectID: "object", Layer: 1, WidthCM: w, HeightCM: h}
+}
+
+func TestPackExactFit(t *testing.T) {
+	layout := Pack([]Piece{piece(1, 20, 30)}, Options{Sheet: testSheet})
+	if layout.SheetCount != 1 || len(layout.Unplaced) != 0 {
+		t.Fatalf("got %d sheets and %d unplaced, want 1 and 0", layout.SheetCount, len(layout.Unplaced))
+	}
+	p := layout.Sheets[0].Placements[0]
+	if p.XCM != 0 || p.YCM != 0 || p.WidthCM != 20 || p.HeightCM != 30 || p.Rotated {
+		t.Errorf("placement = %+v, want the whole sheet upright", p)
+	}
+	if math.Abs(layout.Utilisation-1) > 1e-9 {
+		t.Errorf("utilisation = %g, want 1", layout.Utilisation)
+	}
+}
+
+func TestPackExactFitWithMarginAndBleed(t *testing.T) {
+	opts := Options{Sheet: testSheet, MarginCM: 1, BleedCM: 0.5}
+	layout := Pack([]Piece{piece(1, 17, 27)}, opts)
+	if layout.SheetCount != 1 || len(layout.Unplaced) != 0 {
+		t.Fatalf("got %d sheets and %d unplaced, want 1 and 0", layout.SheetCount, len(layout.Unplaced))
+	}
+	if p := layout.Sheets[0].Placements[0]; p.XCM != 1.5 || p.YCM != 1.5 {
+		t.Errorf("trim box at %g,%g, want 1.5,1.5 inside margin and bleed", p.XCM, p.YCM)
+	}
+}
+
+func TestPackOversized(t *testing.T) {
+	layout := Pack([]Piece{piece(1, 20.1, 30), piece(2, 5, 5)}, Options{Sheet: testSheet, AllowRotation: true})
+	if len(layout.Unplaced) != 1 || layout.Unplaced[0].Order != 1 {
+		t.Fatalf("unplaced = %+v, want piece 1", layout.Unplaced)
+	}
+	if layout.SheetCount != 1 || len(layout.Sheets[0].Placements) != 1 {
+		t.Fatalf("got %d sheets, want piece 2 alone on one", layout.SheetCount)
+	}
+}
+
+func TestPackEmpty(t *testing.T) {
+	layout := Pack(nil, Options{Sheet: testSheet})
+	if layout.SheetCount != 0 || layout.Utilisation != 0 {
+		t.Errorf("got %d sheets at %g utilisation, want none", layout.SheetCount, layout.Utilisation)
+	}
+}
+
+func TestPackRotation(t *testing.T) {
+	wide := piece(1, 30, 20)
+
+	layout := Pack([]Piece{wide}, Options{Sheet: testSheet})
+	if len(layout.Unplaced) != 1 {
+		t.Fatalf("without rotation: unplaced = %+v, want the piece", layout.Unplaced)
+	}
+
+	layout = Pack([]Piece{wide}, Options{Sheet: testSheet, AllowRotation: true})
+	if len(layout.Unplaced) != 0 {
+		t.Fatalf("with rotation: unplaced = %+v, want none", layout.Unplaced)
+	}
+	if p := layout.Sheets[0].Placements[0]; !p.Rotated || p.WidthCM != 20 || p.HeightCM != 30 {
+		t.Errorf("placement = %+v, want rotated to 20x30", p)
+	}
+}
+
+func TestPackFillsSheetsWithoutOverlap(t *testing.T) {
+	var pieces []Piece
+	for i := 1; i <= 7; i++ {
+		pieces = append(pieces, piece(i, 10, 10))
+	}
+	layout := Pack(pieces, Options{Sheet: testSheet})
+	if layout.SheetCount != 2 {
+		t.Fatalf("got %d sheets, want 2 (six squares fit on one)", layout.SheetCount)
+	}
+	if n := len(layout.Sheets[0].Placements); n != 6 {
+		t.Errorf("first sheet holds %d pieces, want 6", n)
+	}
+
+	for _, sheet := range layout.Sheets {
+		for i, a := range sheet.Placements {
+			if a.XCM < 0 || a.YCM < 0 || a.XCM+a.WidthCM > testSheet.WidthCM+epsilon || a.YCM+a.HeightCM > testSheet.HeightCM+epsilon {
+				t.Errorf("sheet %d: %+v lies outside the sheet", sheet.Number, a)
+			}
+			for _, b := range sheet.Placements[i+1:] {
+				if a.XCM < b.XCM+b.WidthCM-epsilon && b.XCM < a.XCM+a.WidthCM-epsilon &&
+					a.YCM < b.YCM+b.HeightCM-epsilon && b.YCM < a.YCM+a.HeightCM-epsilon {
+					t.Errorf("sheet %d: %+v overlaps %+v", sheet.Number, a, b)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package nesting
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Drawing styles shared by the SVG and PDF output: the margin is outlined in
+// grey, bleed boxes are shaded and trim boxes, the cut lines, are drawn in red
+const (
+	labelSizeCM    = 0.35
+	cutLineWidth   = 0.02
+	guideLineWidth = 0.01
+)
+
+// SVG draws one sheet of the layout at its physical size
+func (l *Layout) SVG(sheet *SheetLayout) []byte {
+	var buf bytes.Buffer
+	w, h := l.Sheet.WidthCM, l.Sheet.HeightCM
+
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%scm" height="%scm" viewBox="0 0 %s %s">`+"\n",
+		num(w), num(h), num(w), num(h))
+	fmt.Fprintf(&buf, `<title>%s sheet %d of %d</title>`+"\n", l.Sheet.Name, sheet.Number, l.SheetCount)
+	fmt.Fprintf(&buf, `<rect x="0" y="0" width="%s" height="%s" fill="#ffffff"/>`+"\n", num(w), num(h))
+	if l.MarginCM > 0 {
+		fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="none" stroke="#999999" stroke-width="%s" stroke-dasharray="0.2 0.2"/>`+"\n",
+			num(l.MarginCM), num(l.MarginCM), num(w-2*l.MarginCM), num(h-2*l.MarginCM), num(guideLineWidth))
+	}
+
+	for _, p := range sheet.Placements {
+		fmt.Fprintf(&buf, `<g id="layer-%d">`+"\n", p.Order)
+		if l.BleedCM > 0 {
+			fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="#e8e8e8"/>`+"\n",
+				num(p.XCM-l.BleedCM), num(p.YCM-l.BleedCM), num(p.WidthCM+2*l.BleedCM), num(p.HeightCM+2*l.BleedCM))
+		}
+		fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="none" stroke="#ff0000" stroke-width="%s"/>`+"\n",
+			num(p.XCM), num(p.YCM), num(p.WidthCM), num(p.HeightCM), num(cutLineWidth))
+		fmt.Fprintf(&buf, `<text x="%s" y="%s" font-family="Helvetica, Arial, sans-serif" font-size="%s" fill="#333333">%s</text>`+"\n",
+			num(p.XCM+0.1), num(p.YCM+0.1+labelSizeCM), num(labelSizeCM), label(p))
+		buf.WriteString("</g>\n")
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// PDF draws every sheet of the layout as a page of a PDF document
+func (l *Layout) PDF() []byte {
+	// PDF units are points, measured from the bottom-left corner of the page
+	const ptPerCM = 72 / 2.54
+	pageW, pageH := l.Sheet.WidthCM*ptPerCM, l.Sheet.HeightCM*ptPerCM
+	box := func(x, y, w, h float64) string {
+		return fmt.Sprintf("%s %s %s %s re", num(x*ptPerCM), num(pageH-(y+h)*ptPerCM), num(w*ptPerCM), num(h*ptPerCM))
+	}
+
+	var streams []string
+	for i := range l.Sheets {
+		sheet := &l.Sheets[i]
+		var content bytes.Buffer
+		if l.MarginCM > 0 {
+			fmt.Fprintf(&content, "0.6 G %s w [2 2] 0 d %s S [] 0 d\n", num(guideLineWidth*ptPerCM),
+				box(l.MarginCM, l.MarginCM, l.Sheet.WidthCM-2*l.MarginCM, l.Sheet.HeightCM-2*l.MarginCM))
+		}
+		for _, p := range sheet.Placements {
+			if l.BleedCM > 0 {
+				fmt.Fprintf(&content, "0.91 g %s f\n", box(p.XCM-l.BleedCM, p.YCM-l.BleedCM, p.WidthCM+2*l.BleedCM, p.HeightCM+2*l.BleedCM))
+			}
+			fmt.Fprintf(&content, "1 0 0 RG %s w %s S\n", num(cutLineWidth*ptPerCM), box(p.XCM, p.YCM, p.WidthCM, p.HeightCM))
+			fmt.Fprintf(&content, "0.2 g BT /F1 %s Tf %s %s Td (%s) Tj ET\n", num(labelSizeCM*ptPerCM),
+				num((p.XCM+0.1)*ptPerCM), num(pageH-(p.YCM+0.1+labelSizeCM)*ptPerCM), label(p))
+		}
+		streams = append(streams, content.String())
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3 font, then a page and its content
+	// stream for every sheet
+	var objects []string
+	kids := ""
+	for i := range streams {
+		kids += fmt.Sprintf("%d 0 R ", 4+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(streams)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+	for i, stream := range streams {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				num(pageW), num(pageH), 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(stream), stream),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// label names a placed piece by its assembly order and layer
+func label(p Placement) string {
+	return fmt.Sprintf("#%d layer %d", p.Order, p.Layer)
+}
+
+// num formats a length to three decimals without trailing zeros
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
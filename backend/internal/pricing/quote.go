@@ -0,0 +1,170 @@
+// Package pricing computes the bill of materials and price of building a
+// project, from its production plan and a table of unit rates.
+package pricing
+
+import (
+	"fmt"
+	"math"
+
+	"scrapyuk-backend/internal/nesting"
+	"scrapyuk-backend/internal/production"
+	"scrapyuk-backend/internal/scene"
+)
+
+// Rate keys of the price table
+const (
+	RatePaperSheet  = "paper_sheet"
+	RateLargeFormat = "large_format"
+	RateLayerCut    = "layer_cut"
+	RateFoamSpacer  = "foam_spacer"
+	RateLEDStrip    = "led_strip"
+	RateFrame       = "frame"
+)
+
+// SheetOptions is how layers are nested onto the cardstock priced by the
+// paper_sheet rate: A4 with a margin the printer cannot use on every side and
+// a bleed around every piece
+var SheetOptions = nesting.Options{
+	Sheet:         nesting.Sizes["a4"],
+	MarginCM:      1,
+	BleedCM:       0.3,
+	AllowRotation: true,
+}
+
+// Rate is the price of one unit of a line item
+type Rate struct {
+	Key       string
+	Name      string
+	Unit      string
+	UnitPrice float64
+}
+
+// DefaultRates seed the price table. Prices are in the quote currency.
+var DefaultRates = []Rate{
+	{Key: RatePaperSheet, Name: "Cardstock sheets (A4)", Unit: "sheet", UnitPrice: 0.60},
+	{Key: RateLargeFormat, Name: "Large-format cardstock", Unit: "m2", UnitPrice: 15.00},
+	{Key: RateLayerCut, Name: "Printing and cutting", Unit: "layer", UnitPrice: 1.50},
+	{Key: RateFoamSpacer, Name: "Foam spacers", Unit: "cm3", UnitPrice: 0.01},
+	{Key: RateLEDStrip, Name: "LED strip", Unit: "m", UnitPrice: 8.00},
+	{Key: RateFrame, Name: "Frame", Unit: "m2", UnitPrice: 250.00},
+}
+
+// LineItem is one material or service on a quote
+type LineItem struct {
+	Key       string  `json:"key"`
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit"`
+	Quantity  float64 `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Total     float64 `json:"total"`
+	Note      string  `json:"note,omitempty"`
+}
+
+// Quote is the bill of materials and price of a project
+type Quote struct {
+	Currency string      `json:"currency"`
+	Items    []LineItem  `json:"items"`
+	Total    float64     `json:"total"`
+	Layers   int         `json:"layers"`
+	Skipped  int         `json:"skipped_objects"`
+	Sheets   *SheetUsage `json:"sheets,omitempty"`
+}
+
+// SheetUsage reports how well the layers fill the paper they are printed on
+type SheetUsage struct {
+	Size        string  `json:"size"`
+	Count       int     `json:"count"`
+	Utilisation float64 `json:"utilisation"`
+	Oversized   int     `json:"oversized_layers"`
+}
+
+// Input is everything a quote is calculated from
+type Input struct {
+	Plan     *production.Plan
+	Lighting scene.Lighting
+	// Layout is the plan's layers nested with SheetOptions
+	Layout *nesting.Layout
+}
+
+// Calculate prices the project described by in. rates is the price table;
+// keys missing from it are priced at their DefaultRates.
+func Calculate(in Input, rates []Rate, currency string) Quote {
+	table := make(map[string]Rate, len(DefaultRates))
+	for _, rate := range DefaultRates {
+		table[rate.Key] = rate
+	}
+	for _, rate := range rates {
+		table[rate.Key] = rate
+	}
+
+	plan := in.Plan
+	quote := Quote{
+		Currency: currency,
+		Items:    []LineItem{},
+		Layers:   len(plan.Layers),
+		Skipped:  len(plan.Skipped),
+	}
+	add := func(key string, quantity float64, note string) {
+		rate := table[key]
+		item := LineItem{
+			Key:       key,
+			Name:      rate.Name,
+			Unit:      rate.Unit,
+			Quantity:  quantity,
+			UnitPrice: rate.UnitPrice,
+			Total:     money(quantity * rate.UnitPrice),
+			Note:      note,
+		}
+		quote.Items = append(quote.Items, item)
+		quote.Total += item.Total
+	}
+
+	if len(plan.Layers) > 0 {
+		layout := in.Layout
+		quote.Sheets = &SheetUsage{
+			Size:        layout.Sheet.Name,
+			Count:       layout.SheetCount,
+			Utilisation: layout.Utilisation,
+			Oversized:   len(layout.Unplaced),
+		}
+		if layout.SheetCount > 0 {
+			add(RatePaperSheet, float64(layout.SheetCount), fmt.Sprintf("%.0f%% of the paper used", layout.Utilisation*100))
+		}
+		if len(layout.Unplaced) > 0 {
+			// Layers too large for a sheet are printed on large-format stock
+			// cut to their size, margin and bleed included
+			pad := 2 * (layout.MarginCM + layout.BleedCM)
+			var area float64
+			for _, piece := range layout.Unplaced {
+				area += (piece.WidthCM + pad) * (piece.HeightCM + pad)
+			}
+			add(RateLargeFormat, math.Ceil(area/10)/1000,
+				fmt.Sprintf("%d layer(s) too large for %s sheets", len(layout.Unplaced), layout.Sheet.Name))
+		}
+		add(RateLayerCut, float64(len(plan.Layers)), "")
+	}
+
+	// Foam fills the footprint of every raised layer to its spacer thickness
+	var foam float64
+	for _, layer := range plan.Layers {
+		foam += layer.WidthCM * layer.HeightCM * layer.SpacerCM
+	}
+	if foam > 0 {
+		add(RateFoamSpacer, math.Round(foam*10)/10, "footprint x spacer thickness")
+	}
+
+	frame := plan.Frame
+	if in.Lighting.Enabled {
+		// The strip runs around the inside of the frame
+		add(RateLEDStrip, math.Round(2*(frame.WidthCM+frame.HeightCM))/100, "frame perimeter")
+	}
+	add(RateFrame, frame.WidthCM*frame.HeightCM/10000, frame.Name)
+
+	quote.Total = money(quote.Total)
+	return quote
+}
+
+// money rounds an amount to cents
+func money(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
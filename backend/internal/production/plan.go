@@ -0,0 +1,212 @@
+// Package production turns a scrapbook scene into what is needed to build it
+// by hand: one print per paper layer and a cutting sheet describing how the
+// layers are stacked inside the frame.
+//
+// Offsets on the cutting sheet are measured from the frame base, the
+// bottom-left corner of the back panel, rather than from the scene origin at
+// its centre, so they can be marked out with a ruler.
+package production
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"scrapyuk-backend/internal/scene"
+)
+
+const (
+	// DefaultDPI is the print resolution used when none is requested
+	DefaultDPI = 300
+	// MinDPI and MaxDPI bound the accepted print resolutions
+	MinDPI = 72
+	MaxDPI = 600
+	// MaxPrintDimension is the largest width or height of a rendered layer
+	// in pixels, which bounds the memory needed to render it to 256 MB. It
+	// covers a 33 cm piece at MaxDPI.
+	MaxPrintDimension = 8000
+)
+
+// Frame is the frame a plan is laid out in
+type Frame struct {
+	Name     string  `json:"name"`
+	WidthCM  float64 `json:"width_cm"`
+	HeightCM float64 `json:"height_cm"`
+	DepthCM  float64 `json:"depth_cm"`
+}
+
+// Asset is the original image an object is printed from
+type Asset struct {
+	ID     uint
+	Width  int
+	Height int
+}
+
+// Plan is the cutting sheet for a scene
+type Plan struct {
+	DPI      int       `json:"dpi"`
+	Frame    Frame     `json:"frame"`
+	Layers   []Layer   `json:"layers"`
+	Skipped  []Skipped `json:"skipped"`
+	Warnings []string  `json:"warnings"`
+}
+
+// Layer is one printed and cut piece of paper. Layers are listed in assembly
+// order, from the back panel towards the viewer.
+type Layer struct {
+	Order       int     `json:"order"`
+	File        string  `json:"file"`
+	ObjectID    string  `json:"object_id"`
+	AssetID     uint    `json:"asset_id"`
+	Layer       int     `json:"layer"`
+	LayerCount  int     `json:"layer_count"`
+	WidthCM     float64 `json:"width_cm"`
+	HeightCM    float64 `json:"height_cm"`
+	WidthPx     int     `json:"width_px"`
+	HeightPx    int     `json:"height_px"`
+	SourceDPI   float64 `json:"source_dpi"`
+	CenterXCM   float64 `json:"center_x_cm"`
+	CenterYCM   float64 `json:"center_y_cm"`
+	OffsetXCM   float64 `json:"offset_x_cm"`
+	OffsetYCM   float64 `json:"offset_y_cm"`
+	OffsetZCM   float64 `json:"offset_z_cm"`
+	SpacerCM    float64 `json:"spacer_cm"`
+	RotationZ   float64 `json:"rotation_z"`
+	ObjectIndex int     `json:"-"`
+}
+
+// Skipped is a scene object that produces no layers
+type Skipped struct {
+	ObjectID string `json:"object_id"`
+	Reason   string `json:"reason"`
+}
+
+// Build lays out every layer of the scene's objects at the given DPI. assets
+// maps the scene's asset references to their originals. Objects without a
+// known asset are listed as skipped.
+func Build(doc *scene.Document, frame Frame, assets map[scene.Ref]Asset, dpi int) *Plan {
+	plan := &Plan{
+		DPI:      dpi,
+		Frame:    frame,
+		Layers:   []Layer{},
+		Skipped:  []Skipped{},
+		Warnings: []string{},
+	}
+
+	for i := range doc.Objects {
+		obj := &doc.Objects[i]
+		if obj.AssetID == "" {
+			plan.Skipped = append(plan.Skipped, Skipped{ObjectID: string(obj.ID), Reason: "object has no asset"})
+			continue
+		}
+		asset, ok := assets[obj.AssetID]
+		if !ok {
+			plan.Skipped = append(plan.Skipped, Skipped{ObjectID: string(obj.ID), Reason: "asset " + string(obj.AssetID) + " not found"})
+			continue
+		}
+
+		widthCM, heightCM := obj.SizeCM(asset.Width, asset.Height)
+		widthPx, heightPx := pixels(widthCM, dpi), pixels(heightCM, dpi)
+
+		centerX := obj.Position.X + frame.WidthCM/2
+		centerY := obj.Position.Y + frame.HeightCM/2
+		offsetX, offsetY := centerX-widthCM/2, centerY-heightCM/2
+		if offsetX < 0 || offsetY < 0 || offsetX+widthCM > frame.WidthCM || offsetY+heightCM > frame.HeightCM {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("object %s extends past the frame edge and must be trimmed", obj.ID))
+		}
+		sourceDPI := float64(scene.ReferenceDPI) / obj.Scale
+		if sourceDPI < float64(dpi)/2 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("object %s is enlarged to %.0f dpi of source detail and may print blurry", obj.ID, sourceDPI))
+		}
+
+		var rotation float64
+		if obj.Rotation != nil {
+			rotation = obj.Rotation.Z
+		}
+
+		layers := max(obj.Layers, 1)
+		for layer := 1; layer <= layers; layer++ {
+			// The back layer is raised off the panel by the object's own z;
+			// every following layer sits one spacing above the previous one
+			spacer := obj.LayerSpacing
+			if layer == 1 {
+				spacer = obj.Position.Z
+			}
+			plan.Layers = append(plan.Layers, Layer{
+				ObjectID:    string(obj.ID),
+				AssetID:     asset.ID,
+				Layer:       layer,
+				LayerCount:  layers,
+				WidthCM:     round(widthCM),
+				HeightCM:    round(heightCM),
+				WidthPx:     widthPx,
+				HeightPx:    heightPx,
+				SourceDPI:   math.Round(sourceDPI),
+				CenterXCM:   round(centerX),
+				CenterYCM:   round(centerY),
+				OffsetXCM:   round(offsetX),
+				OffsetYCM:   round(offsetY),
+				OffsetZCM:   round(obj.Position.Z + float64(layer-1)*obj.LayerSpacing),
+				SpacerCM:    round(spacer),
+				RotationZ:   rotation,
+				ObjectIndex: i,
+			})
+		}
+	}
+
+	// Pieces are glued from the back panel forwards
+	sort.SliceStable(plan.Layers, func(a, b int) bool {
+		return plan.Layers[a].OffsetZCM < plan.Layers[b].OffsetZCM
+	})
+	for i := range plan.Layers {
+		layer := &plan.Layers[i]
+		layer.Order = i + 1
+		layer.File = fmt.Sprintf("layers/%03d-object%d-layer%d.png", layer.Order, layer.ObjectIndex+1, layer.Layer)
+	}
+
+	return plan
+}
+
+// CheckPrintSize returns an error if any layer is too large to render
+func (p *Plan) CheckPrintSize() error {
+	for _, l := range p.Layers {
+		if l.WidthPx > MaxPrintDimension || l.HeightPx > MaxPrintDimension {
+			return fmt.Errorf("object %s would print at %dx%d px, above the %dpx limit; lower the dpi",
+				l.ObjectID, l.WidthPx, l.HeightPx, MaxPrintDimension)
+		}
+	}
+	return nil
+}
+
+// CSV returns the plan's layers as cutting sheet rows, header first
+func (p *Plan) CSV() [][]string {
+	rows := [][]string{{
+		"order", "file", "object_id", "asset_id", "layer", "layer_count",
+		"width_cm", "height_cm", "offset_x_cm", "offset_y_cm", "offset_z_cm",
+		"spacer_cm", "rotation_z",
+	}}
+	for _, l := range p.Layers {
+		rows = append(rows, []string{
+			strconv.Itoa(l.Order), l.File, l.ObjectID, strconv.FormatUint(uint64(l.AssetID), 10),
+			strconv.Itoa(l.Layer), strconv.Itoa(l.LayerCount),
+			formatCM(l.WidthCM), formatCM(l.HeightCM), formatCM(l.OffsetXCM), formatCM(l.OffsetYCM),
+			formatCM(l.OffsetZCM), formatCM(l.SpacerCM), strconv.FormatFloat(l.RotationZ, 'f', -1, 64),
+		})
+	}
+	return rows
+}
+
+// pixels converts a length in cm to a pixel count at dpi, never below 1
+func pixels(cm float64, dpi int) int {
+	return max(1, int(math.Round(cm/scene.CMPerInch*float64(dpi))))
+}
+
+// round rounds a length in cm to a tenth of a millimetre
+func round(cm float64) float64 {
+	return math.Round(cm*100) / 100
+}
+
+func formatCM(cm float64) string {
+	return strconv.FormatFloat(cm, 'f', 2, 64)
+}
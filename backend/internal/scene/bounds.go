@@ -0,0 +1,66 @@
+package scene
+
+import (
+	"math"
+	"strconv"
+)
+
+// ReferenceDPI is the print resolution of an asset at scale 1: each pixel of the
+// original PNG covers 1/ReferenceDPI of an inch inside the frame
+const ReferenceDPI = 300
+
+// CMPerInch converts between print resolutions and scene units
+const CMPerInch = 2.54
+
+// Bounds is the interior of a frame, in centimetres
+type Bounds struct {
+	Width     float64
+	Height    float64
+	Depth     float64
+	MaxLayers int
+}
+
+// StackDepth returns the z coordinate of the object's front layer. Layers are
+// stacked from Position.Z towards the viewer, LayerSpacing apart.
+func (o *Object) StackDepth() float64 {
+	layers := o.Layers
+	if layers < 1 {
+		layers = 1
+	}
+	return o.Position.Z + float64(layers-1)*o.LayerSpacing
+}
+
+// SizeCM returns the printed width and height of the object's asset, given the
+// pixel size of the original PNG
+func (o *Object) SizeCM(widthPx, heightPx int) (float64, float64) {
+	perPixel := o.Scale * CMPerInch / ReferenceDPI
+	return float64(widthPx) * perPixel, float64(heightPx) * perPixel
+}
+
+// CheckBounds reports every object whose position or layer stack does not fit
+// inside the frame. Objects are positioned by their centre, so x and y must lie
+// within half the frame's width and height, and the whole stack must lie
+// between the back panel and the front of the frame.
+func (d *Document) CheckBounds(b Bounds) error {
+	var errs ValidationErrors
+
+	halfWidth, halfHeight := b.Width/2, b.Height/2
+	for i, obj := range d.Objects {
+		field := "objects." + strconv.Itoa(i)
+		if math.Abs(obj.Position.X) > halfWidth || math.Abs(obj.Position.Y) > halfHeight {
+			errs.add(field+".position", "must lie within the %gx%g cm frame (|x| <= %g, |y| <= %g)",
+				b.Width, b.Height, halfWidth, halfHeight)
+		}
+		if obj.Position.Z < 0 || obj.StackDepth() > b.Depth {
+			errs.add(field+".position.z", "layer stack must lie between 0 and the frame depth of %g cm", b.Depth)
+		}
+		if b.MaxLayers > 0 && obj.Layers > b.MaxLayers {
+			errs.add(field+".layers", "must not exceed the frame's %d layers", b.MaxLayers)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
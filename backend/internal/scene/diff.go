@@ -0,0 +1,101 @@
+package scene
+
+import (
+	"reflect"
+)
+
+// Diff describes the scene-level differences between two documents
+type Diff struct {
+	Added    []Object       `json:"added"`
+	Removed  []Object       `json:"removed"`
+	Modified []ObjectChange `json:"modified"`
+	Settings []string       `json:"settings_changed"`
+	Camera   bool           `json:"camera_changed"`
+}
+
+// ObjectChange describes an object present in both documents whose fields differ
+type ObjectChange struct {
+	ID     Ref      `json:"id"`
+	Fields []string `json:"fields"`
+	Before Object   `json:"before"`
+	After  Object   `json:"after"`
+}
+
+// Empty reports whether the documents were identical at the scene level
+func (d *Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0 &&
+		len(d.Settings) == 0 && !d.Camera
+}
+
+// Compare returns the differences needed to go from one document to another.
+// Objects are matched by ID.
+func Compare(from, to *Document) Diff {
+	diff := Diff{
+		Added:    []Object{},
+		Removed:  []Object{},
+		Modified: []ObjectChange{},
+		Settings: []string{},
+	}
+
+	before := make(map[Ref]Object, len(from.Objects))
+	for _, obj := range from.Objects {
+		before[obj.ID] = obj
+	}
+	after := make(map[Ref]bool, len(to.Objects))
+
+	for _, obj := range to.Objects {
+		after[obj.ID] = true
+		old, ok := before[obj.ID]
+		if !ok {
+			diff.Added = append(diff.Added, obj)
+			continue
+		}
+		if fields := changedObjectFields(old, obj); len(fields) > 0 {
+			diff.Modified = append(diff.Modified, ObjectChange{
+				ID:     obj.ID,
+				Fields: fields,
+				Before: old,
+				After:  obj,
+			})
+		}
+	}
+
+	for _, obj := range from.Objects {
+		if !after[obj.ID] {
+			diff.Removed = append(diff.Removed, obj)
+		}
+	}
+
+	if from.Settings.BackgroundColor != to.Settings.BackgroundColor {
+		diff.Settings = append(diff.Settings, "backgroundColor")
+	}
+	if !reflect.DeepEqual(from.Settings.Lighting, to.Settings.Lighting) {
+		diff.Settings = append(diff.Settings, "lighting")
+	}
+	diff.Camera = from.Camera != to.Camera
+
+	return diff
+}
+
+func changedObjectFields(a, b Object) []string {
+	var fields []string
+	if a.AssetID != b.AssetID {
+		fields = append(fields, "assetId")
+	}
+	if a.Position != b.Position {
+		fields = append(fields, "position")
+	}
+	if a.Scale != b.Scale {
+		fields = append(fields, "scale")
+	}
+	if a.Layers != b.Layers {
+		fields = append(fields, "layers")
+	}
+	if a.LayerSpacing != b.LayerSpacing {
+		fields = append(fields, "layerSpacing")
+	}
+	if !reflect.DeepEqual(a.Rotation, b.Rotation) {
+		fields = append(fields, "rotation")
+	}
+	return fields
+}
@@ -0,0 +1,292 @@
+// Package scene defines the versioned schema of Project.ProjectData, the JSON
+// document the editor saves for a scrapbook scene.
+//
+// Coordinates are in centimetres with the origin at the centre of the frame's
+// back panel: x runs left to right, y bottom to top and z out of the frame
+// towards the viewer.
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxLayers is the largest number of stacked paper layers per object
+const MaxLayers = 10
+
+// Document is the typed form of a project's scene data
+type Document struct {
+	Version  string   `json:"version"`
+	Settings Settings `json:"settings"`
+	Objects  []Object `json:"objects"`
+	Camera   Camera   `json:"camera"`
+}
+
+// Settings holds scene-wide presentation settings
+type Settings struct {
+	BackgroundColor string   `json:"backgroundColor"`
+	Lighting        Lighting `json:"lighting"`
+}
+
+// Lighting describes the LED lighting inside the frame
+type Lighting struct {
+	Enabled   bool      `json:"enabled"`
+	Intensity float64   `json:"intensity"`
+	Color     string    `json:"color,omitempty"`
+	Positions []Vector3 `json:"positions"`
+}
+
+// Object is a placed asset in the scene
+type Object struct {
+	ID           Ref      `json:"id"`
+	AssetID      Ref      `json:"assetId,omitempty"`
+	Position     Vector3  `json:"position"`
+	Scale        float64  `json:"scale"`
+	Layers       int      `json:"layers"`
+	LayerSpacing float64  `json:"layerSpacing"`
+	Rotation     *Vector3 `json:"rotation,omitempty"`
+}
+
+// Camera is the saved editor viewpoint
+type Camera struct {
+	Position Vector3 `json:"position"`
+	Target   Vector3 `json:"target"`
+	FOV      float64 `json:"fov"`
+}
+
+// Vector3 is a point or direction in scene coordinates
+type Vector3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// Ref is an identifier that the editor may send either as a string or a number
+type Ref string
+
+// UnmarshalJSON accepts both JSON strings and numbers
+func (r *Ref) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*r = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*r = Ref(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("must be a string or number")
+	}
+	*r = Ref(n.String())
+	return nil
+}
+
+// FieldError describes a validation failure on a single field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects every field that failed validation
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, fe := range v {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (v *ValidationErrors) add(field, format string, args ...interface{}) {
+	*v = append(*v, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Validate checks the document against the current schema
+func (d *Document) Validate() error {
+	var errs ValidationErrors
+
+	if d.Version != CurrentVersion {
+		errs.add("version", "must be %q", CurrentVersion)
+	}
+
+	if d.Settings.BackgroundColor != "" && !hexColor.MatchString(d.Settings.BackgroundColor) {
+		errs.add("settings.backgroundColor", "must be a hex color such as #ffffff")
+	}
+
+	lighting := d.Settings.Lighting
+	if lighting.Intensity < 0 || lighting.Intensity > 10 {
+		errs.add("settings.lighting.intensity", "must be between 0 and 10")
+	}
+	if lighting.Color != "" && !hexColor.MatchString(lighting.Color) {
+		errs.add("settings.lighting.color", "must be a hex color such as #ffffff")
+	}
+	for i, p := range lighting.Positions {
+		checkVector(&errs, "settings.lighting.positions."+strconv.Itoa(i), p)
+	}
+
+	ids := make(map[Ref]bool, len(d.Objects))
+	for i, obj := range d.Objects {
+		field := "objects." + strconv.Itoa(i)
+		if obj.ID == "" {
+			errs.add(field+".id", "is required")
+		} else if ids[obj.ID] {
+			errs.add(field+".id", "duplicate object id %q", obj.ID)
+		}
+		ids[obj.ID] = true
+
+		checkVector(&errs, field+".position", obj.Position)
+		if obj.Scale <= 0 {
+			errs.add(field+".scale", "must be greater than 0")
+		}
+		if obj.Layers < 1 || obj.Layers > MaxLayers {
+			errs.add(field+".layers", "must be between 1 and %d", MaxLayers)
+		}
+		if obj.LayerSpacing < 0 {
+			errs.add(field+".layerSpacing", "must not be negative")
+		}
+		if obj.Rotation != nil {
+			checkVector(&errs, field+".rotation", *obj.Rotation)
+		}
+	}
+
+	checkVector(&errs, "camera.position", d.Camera.Position)
+	checkVector(&errs, "camera.target", d.Camera.Target)
+	if d.Camera.FOV <= 0 || d.Camera.FOV >= 180 {
+		errs.add("camera.fov", "must be between 0 and 180 degrees")
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func checkVector(errs *ValidationErrors, field string, v Vector3) {
+	for _, value := range []float64{v.X, v.Y, v.Z} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			errs.add(field, "must contain finite numbers")
+			return
+		}
+	}
+}
+
+// Parse decodes raw scene JSON into a typed document, upgrading older versions
+// first. Type mismatches are reported as ValidationErrors.
+func Parse(raw json.RawMessage) (*Document, error) {
+	upgraded, err := Upgrade(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document
+	if err := json.Unmarshal(upgraded, &doc); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, ValidationErrors{{
+				Field:   typeErr.Field,
+				Message: "must be " + jsonTypeName(typeErr.Type),
+			}}
+		}
+		return nil, ValidationErrors{{Field: "", Message: err.Error()}}
+	}
+
+	return &doc, nil
+}
+
+// jsonTypeName describes a Go type using JSON terminology for error messages
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// Normalize upgrades raw scene JSON to the current version and validates it.
+// The returned JSON keeps any fields the schema does not know about.
+func Normalize(raw json.RawMessage) (json.RawMessage, error) {
+	upgraded, err := Upgrade(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := Parse(upgraded)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+
+	return upgraded, nil
+}
+
+// Default returns an empty scene document at the current version
+func Default() json.RawMessage {
+	raw, _ := Upgrade(json.RawMessage(`{}`))
+	return raw
+}
+
+// RemapAssets rewrites the assetId of every object through mapping, keeping
+// all other fields as they are. Objects whose asset is not in mapping lose
+// their assetId, so a copied scene never points at another project's assets.
+func RemapAssets(raw json.RawMessage, mapping map[Ref]Ref) (json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return raw, nil
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, ValidationErrors{{Field: "", Message: "project data must be a JSON object"}}
+	}
+
+	objects, _ := doc["objects"].([]interface{})
+	for _, item := range objects {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var old Ref
+		switch id := obj["assetId"].(type) {
+		case string:
+			old = Ref(id)
+		case float64:
+			old = Ref(strconv.FormatFloat(id, 'f', -1, 64))
+		default:
+			continue
+		}
+
+		if mapped, ok := mapping[old]; ok {
+			obj["assetId"] = string(mapped)
+		} else {
+			delete(obj, "assetId")
+		}
+	}
+
+	return json.Marshal(doc)
+}
@@ -0,0 +1,152 @@
+package scene
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CurrentVersion is the scene schema version written by the server
+const CurrentVersion = "1.1"
+
+// Migration upgrades a generic scene document from one version to the next.
+// Migrations operate on the decoded JSON so fields unknown to the Go schema
+// are carried through unchanged.
+type Migration struct {
+	From        string
+	To          string
+	Description string
+	Up          func(doc map[string]interface{}) error
+}
+
+var migrations = map[string]Migration{}
+
+// Register adds a migration to the registry. Registering two migrations from
+// the same version panics, since the upgrade path must be unambiguous.
+func Register(m Migration) {
+	if _, exists := migrations[m.From]; exists {
+		panic(fmt.Sprintf("scene: duplicate migration from version %q", m.From))
+	}
+	migrations[m.From] = m
+}
+
+// Upgrade applies registered migrations until the document reaches
+// CurrentVersion. Documents without a version are treated as the original
+// unversioned format. An empty input yields an empty output.
+func Upgrade(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return raw, nil
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, ValidationErrors{{Field: "", Message: "project data must be a JSON object"}}
+	}
+
+	version, ok := doc["version"].(string)
+	if !ok && doc["version"] != nil {
+		return nil, ValidationErrors{{Field: "version", Message: "must be a string"}}
+	}
+	if version == CurrentVersion {
+		return raw, nil
+	}
+
+	// Guard against cycles in the registry
+	for steps := 0; version != CurrentVersion; steps++ {
+		m, ok := migrations[version]
+		if !ok || steps > len(migrations) {
+			return nil, ValidationErrors{{Field: "version", Message: fmt.Sprintf("unsupported version %q", version)}}
+		}
+		if err := m.Up(doc); err != nil {
+			return nil, fmt.Errorf("migrating scene from %q to %q: %w", m.From, m.To, err)
+		}
+		doc["version"] = m.To
+		version = m.To
+	}
+
+	return json.Marshal(doc)
+}
+
+func init() {
+	Register(Migration{
+		From:        "",
+		To:          "1.0",
+		Description: "Add settings and objects to unversioned documents",
+		Up: func(doc map[string]interface{}) error {
+			settings, err := objectAt(doc, "settings", "settings")
+			if err != nil {
+				return err
+			}
+			setDefault(settings, "backgroundColor", "#ffffff")
+			lighting, err := objectAt(settings, "lighting", "settings.lighting")
+			if err != nil {
+				return err
+			}
+			setDefault(lighting, "enabled", true)
+			setDefault(lighting, "intensity", 1.0)
+			setDefault(doc, "objects", []interface{}{})
+			return nil
+		},
+	})
+
+	Register(Migration{
+		From:        "1.0",
+		To:          "1.1",
+		Description: "Add camera, lighting positions and per-object defaults",
+		Up: func(doc map[string]interface{}) error {
+			settings, err := objectAt(doc, "settings", "settings")
+			if err != nil {
+				return err
+			}
+			lighting, err := objectAt(settings, "lighting", "settings.lighting")
+			if err != nil {
+				return err
+			}
+			setDefault(lighting, "positions", []interface{}{})
+
+			setDefault(doc, "camera", map[string]interface{}{
+				"position": map[string]interface{}{"x": 0.0, "y": 0.0, "z": 60.0},
+				"target":   map[string]interface{}{"x": 0.0, "y": 0.0, "z": 0.0},
+				"fov":      45.0,
+			})
+
+			// Objects that are not an array cannot be upgraded; replacing
+			// them would lose the saved scene
+			objects, ok := doc["objects"].([]interface{})
+			if !ok && doc["objects"] != nil {
+				return ValidationErrors{{Field: "objects", Message: "must be an array"}}
+			}
+			for _, item := range objects {
+				if obj, ok := item.(map[string]interface{}); ok {
+					setDefault(obj, "scale", 1.0)
+					setDefault(obj, "layers", 1)
+					setDefault(obj, "layerSpacing", 0.5)
+				}
+			}
+			if objects == nil {
+				doc["objects"] = []interface{}{}
+			}
+			return nil
+		},
+	})
+}
+
+// objectAt returns the nested object stored under key, creating it if missing
+// or null. Any other value cannot be upgraded and is reported under field
+// rather than replaced, so the saved data is kept.
+func objectAt(doc map[string]interface{}, key, field string) (map[string]interface{}, error) {
+	if obj, ok := doc[key].(map[string]interface{}); ok {
+		return obj, nil
+	}
+	if doc[key] != nil {
+		return nil, ValidationErrors{{Field: field, Message: "must be an object"}}
+	}
+	obj := map[string]interface{}{}
+	doc[key] = obj
+	return obj, nil
+}
+
+func setDefault(doc map[string]interface{}, key string, value interface{}) {
+	if _, ok := doc[key]; !ok {
+		doc[key] = value
+	}
+}
@@ -0,0 +1,155 @@
+package scene
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func upgradeMap(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	upgraded, err := Upgrade(json.RawMessage(raw))
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(upgraded, &doc); err != nil {
+		t.Fatalf("upgraded document %s is not an object: %v", upgraded, err)
+	}
+	return doc
+}
+
+func TestUpgrade10Defaults(t *testing.T) {
+	doc := upgradeMap(t, `{
+		"version": "1.0",
+		"settings": {"backgroundColor": "#000000", "lighting": {"enabled": false, "intensity": 2}},
+		"objects": [{"id": "a", "position": {"x": 1, "y": 2, "z": 3}}],
+		"custom": "kept"
+	}`)
+
+	if doc["version"] != CurrentVersion {
+		t.Errorf("version = %v, want %q", doc["version"], CurrentVersion)
+	}
+	if doc["custom"] != "kept" {
+		t.Errorf("unknown field custom = %v, want it carried through", doc["custom"])
+	}
+
+	camera, ok := doc["camera"].(map[string]interface{})
+	if !ok || camera["fov"] != 45.0 {
+		t.Errorf("camera = %v, want the default camera", doc["camera"])
+	}
+
+	settings := doc["settings"].(map[string]interface{})
+	if settings["backgroundColor"] != "#000000" {
+		t.Errorf("backgroundColor = %v, want the saved colour", settings["backgroundColor"])
+	}
+	lighting := settings["lighting"].(map[string]interface{})
+	if lighting["enabled"] != false || lighting["intensity"] != 2.0 {
+		t.Errorf("lighting = %v, want the saved values", lighting)
+	}
+	if positions, ok := lighting["positions"].([]interface{}); !ok || len(positions) != 0 {
+		t.Errorf("lighting.positions = %v, want []", lighting["positions"])
+	}
+
+	obj := doc["objects"].([]interface{})[0].(map[string]interface{})
+	want := map[string]interface{}{
+		"id":           "a",
+		"position":     map[string]interface{}{"x": 1.0, "y": 2.0, "z": 3.0},
+		"scale":        1.0,
+		"layers":       1.0,
+		"layerSpacing": 0.5,
+	}
+	if !reflect.DeepEqual(obj, want) {
+		t.Errorf("object = %v, want %v", obj, want)
+	}
+}
+
+func TestUpgrade10KeepsObjectValues(t *testing.T) {
+	doc := upgradeMap(t, `{"version": "1.0", "objects": [{"id": "a", "scale": 2, "layers": 3, "layerSpacing": 1}]}`)
+
+	obj := doc["objects"].([]interface{})[0].(map[string]interface{})
+	if obj["scale"] != 2.0 || obj["layers"] != 3.0 || obj["layerSpacing"] != 1.0 {
+		t.Errorf("object = %v, want its own scale, layers and layerSpacing", obj)
+	}
+}
+
+func TestUpgrade10MissingOrNull(t *testing.T) {
+	for _, raw := range []string{
+		`{"version": "1.0"}`,
+		`{"version": "1.0", "settings": null, "objects": null}`,
+		`{"version": "1.0", "settings": {"lighting": null}}`,
+	} {
+		doc := upgradeMap(t, raw)
+		if objects, ok := doc["objects"].([]interface{}); !ok || len(objects) != 0 {
+			t.Errorf("%s: objects = %v, want []", raw, doc["objects"])
+		}
+		settings, ok := doc["settings"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s: settings = %v, want an object", raw, doc["settings"])
+		}
+		if _, ok := settings["lighting"].(map[string]interface{}); !ok {
+			t.Errorf("%s: settings.lighting = %v, want an object", raw, settings["lighting"])
+		}
+	}
+}
+
+// Values a migration cannot convert fail the upgrade instead of being replaced
+func TestUpgrade10RejectsWrongTypes(t *testing.T) {
+	tests := []struct {
+		raw   string
+		field string
+	}{
+		{`{"version": "1.0", "objects": {"a": 1}}`, "objects"},
+		{`{"version": "1.0", "objects": "x"}`, "objects"},
+		{`{"version": "1.0", "settings": "dark"}`, "settings"},
+		{`{"version": "1.0", "settings": []}`, "settings"},
+		{`{"version": "1.0", "settings": {"lighting": true}}`, "settings.lighting"},
+	}
+
+	for _, tt := range tests {
+		upgraded, err := Upgrade(json.RawMessage(tt.raw))
+		if err == nil {
+			t.Errorf("%s: upgraded to %s, want an error", tt.raw, upgraded)
+			continue
+		}
+		var fieldErrs ValidationErrors
+		if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 || fieldErrs[0].Field != tt.field {
+			t.Errorf("%s: err = %v, want a validation error on %s", tt.raw, err, tt.field)
+		}
+	}
+}
+
+func TestUpgradeUnversioned(t *testing.T) {
+	doc := upgradeMap(t, `{}`)
+
+	if doc["version"] != CurrentVersion {
+		t.Errorf("version = %v, want %q", doc["version"], CurrentVersion)
+	}
+	settings := doc["settings"].(map[string]interface{})
+	if settings["backgroundColor"] != "#ffffff" {
+		t.Errorf("backgroundColor = %v, want the default", settings["backgroundColor"])
+	}
+	if _, ok := doc["camera"]; !ok {
+		t.Error("camera missing")
+	}
+}
+
+func TestUpgradeCurrentIsUnchanged(t *testing.T) {
+	raw := json.RawMessage(`{"version": "1.1", "objects": "not checked here"}`)
+	upgraded, err := Upgrade(raw)
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	if string(upgraded) != string(raw) {
+		t.Errorf("got %s, want the document unchanged", upgraded)
+	}
+}
+
+func TestUpgradeUnsupportedVersion(t *testing.T) {
+	for _, raw := range []string{`{"version": "9.9"}`, `{"version": 1}`, `[]`} {
+		if upgraded, err := Upgrade(json.RawMessage(raw)); err == nil {
+			t.Errorf("%s: upgraded to %s, want an error", raw, upgraded)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrAlreadyStarted is returned when registering a job after Start
+var ErrAlreadyStarted = errors.New("scheduler already started")
+
+// Job is a periodic maintenance task
+type Job struct {
+	// Name identifies the job in logs and status reports
+	Name string
+	// Interval is the time between the end of one run and the start of the next
+	Interval time.Duration
+	// Jitter adds a random delay of up to this duration before every run, so
+	// jobs and replicas do not all fire at the same moment
+	Jitter time.Duration
+	// Run performs the work. ctx is cancelled when the scheduler stops.
+	Run func(ctx context.Context) error
+}
+
+// Locker provides mutual exclusion for job runs across processes, such as
+// several server replicas sharing one database
+type Locker interface {
+	// TryLock acquires the named lock for at most ttl and reports whether it was acquired
+	TryLock(ctx context.Context, name string, ttl time.Duration) (bool, error)
+	// Unlock releases a lock acquired by TryLock
+	Unlock(ctx context.Context, name string) error
+}
+
+// JobStatus reports the state of a registered job
+type JobStatus struct {
+	Name           string     `json:"name"`
+	Interval       string     `json:"interval"`
+	Running        bool       `json:"running"`
+	Runs           int        `json:"runs"`
+	Failures       int        `json:"failures"`
+	Skipped        int        `json:"skipped"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastDuration   string     `json:"last_duration,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastSuccessAt  *time.Time `json:"last_success_at,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
+
+// entry pairs a job with its status
+type entry struct {
+	job    Job
+	mu     sync.Mutex // guards status
+	status JobStatus
+}
+
+// Scheduler runs registered jobs periodically in background goroutines. Each
+// job runs in its own goroutine, so runs of one job never overlap within a
+// process; the optional Locker extends that guarantee across processes.
+type Scheduler struct {
+	locker  Locker
+	mu      sync.Mutex
+	entries []*entry
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates an empty scheduler. locker may be nil when only one process runs jobs.
+func New(locker Locker) *Scheduler {
+	return &Scheduler{locker: locker}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Interval <= 0 || job.Run == nil {
+		return errors.New("job needs a name, a positive interval and a run function")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrAlreadyStarted
+	}
+	s.entries = append(s.entries, &entry{
+		job:    job,
+		status: JobStatus{Name: job.Name, Interval: job.Interval.String()},
+	})
+	return nil
+}
+
+// Start launches every registered job. The first run of each job happens
+// after its jitter delay rather than a full interval.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+	log.Printf("Scheduler started with %d job(s)", len(s.entries))
+}
+
+// Stop cancels running jobs and waits for them to return, or for ctx to be done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status returns a snapshot of every job's status, sorted by name
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	entries := append([]*entry(nil), s.entries...)
+	s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(entries))
+	for _, e := range entries {
+		e.mu.Lock()
+		statuses = append(statuses, e.status)
+		e.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// loop waits for each run's delay and runs the job until ctx is cancelled
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer s.wg.Done()
+
+	delay := jitter(e.job.Jitter)
+	for {
+		next := time.Now().Add(delay)
+		e.mu.Lock()
+		e.status.NextRunAt = &next
+		e.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(ctx, e)
+		delay = e.job.Interval + jitter(e.job.Jitter)
+	}
+}
+
+// run executes the job once, skipping it if another process holds its lock
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	if s.locker != nil {
+		// A run longer than the interval is already overdue, so the lease
+		// expiring then lets another process take over after a crash
+		acquired, err := s.locker.TryLock(ctx, e.job.Name, e.job.Interval)
+		if err != nil || !acquired {
+			e.mu.Lock()
+			e.status.Skipped++
+			e.mu.Unlock()
+			if err != nil {
+				log.Printf("Job %s skipped: could not acquire lock: %v", e.job.Name, err)
+			}
+			return
+		}
+		defer func() {
+			// Release with a fresh context so a cancelled run still unlocks
+			if err := s.locker.Unlock(context.Background(), e.job.Name); err != nil {
+				log.Printf("Job %s: failed to release lock: %v", e.job.Name, err)
+			}
+		}()
+	}
+
+	started := time.Now()
+	e.mu.Lock()
+	e.status.Running = true
+	e.status.LastStartedAt = &started
+	e.mu.Unlock()
+
+	err := safeRun(ctx, e.job)
+
+	finished := time.Now()
+	e.mu.Lock()
+	e.status.Running = false
+	e.status.Runs++
+	e.status.LastFinishedAt = &finished
+	e.status.LastDuration = finished.Sub(started).Round(time.Millisecond).String()
+	if err != nil {
+		e.status.Failures++
+		e.status.LastError = err.Error()
+	} else {
+		e.status.LastError = ""
+		e.status.LastSuccessAt = &finished
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Job %s failed: %v", e.job.Name, err)
+	}
+}
+
+// safeRun runs the job, turning a panic into an error so one bad run does not
+// take down the server
+func safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// jitter returns a random duration in [0, max)
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
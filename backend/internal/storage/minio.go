@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// MinIOStorage stores objects in a MinIO or S3-compatible bucket
+type MinIOStorage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewMinIOStorage creates a storage backend on top of an initialized MinIO client
+func NewMinIOStorage(client *minio.Client, bucket string) *MinIOStorage {
+	return &MinIOStorage{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+// Name implements Storage
+func (s *MinIOStorage) Name() string {
+	return "minio"
+}
+
+// Put implements Storage
+func (s *MinIOStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+// Get implements Storage
+func (s *MinIOStorage) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, ObjectInfo{}, translateMinIOError(err)
+	}
+
+	// GetObject is lazy, so Stat is what actually surfaces missing keys
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, ObjectInfo{}, translateMinIOError(err)
+	}
+
+	return object, toObjectInfo(info), nil
+}
+
+// Stat implements Storage
+func (s *MinIOStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, translateMinIOError(err)
+	}
+	return toObjectInfo(info), nil
+}
+
+// Copy implements Storage. The copy is made server-side without downloading the object.
+func (s *MinIOStorage) Copy(ctx context.Context, src, dst string) error {
+	_, err := s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: s.bucket, Object: dst},
+		minio.CopySrcOptions{Bucket: s.bucket, Object: src},
+	)
+	return translateMinIOError(err)
+}
+
+// Delete implements Storage
+func (s *MinIOStorage) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// List implements Storage
+func (s *MinIOStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		objects = append(objects, toObjectInfo(info))
+	}
+	return objects, nil
+}
+
+// PresignGet implements Storage
+func (s *MinIOStorage) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// HealthCheck implements Storage
+func (s *MinIOStorage) HealthCheck(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("MinIO health check failed: %w", err)
+	}
+
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.bucket)
+	}
+
+	return nil
+}
+
+func toObjectInfo(info minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}
+}
+
+func translateMinIOError(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ErrPresignNotSupported is returned by backends that cannot issue presigned URLs
+var ErrPresignNotSupported = errors.New("presigned URLs are not supported by this storage backend")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Storage is the interface implemented by asset storage backends
+type Storage interface {
+	// Name returns a short identifier for the backend (e.g. "minio", "local")
+	Name() string
+
+	// Put stores the content of r under key. size may be -1 if unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the object stored under key. The caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+
+	// Stat returns metadata for the object stored under key
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+
+	// Copy duplicates the object stored under src to dst, replacing any object at dst
+	Copy(ctx context.Context, src, dst string) error
+
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// List returns all objects whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+
+	// PresignGet returns a time-limited URL for downloading the object directly
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+
+	// HealthCheck verifies the backend is reachable and usable
+	HealthCheck(ctx context.Context) error
+}
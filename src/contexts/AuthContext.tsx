@@ -4,16 +4,15 @@ import React, { createContext, useContext, useEffect, useState, ReactNode } from
 import { AuthState, LoginCredentials, User } from '@/types';
 import { 
   authenticate, 
+  fetchCurrentUser, 
   getCurrentUser, 
-  isAuthenticated, 
-  logout as authLogout,
-  refreshSession 
+  logout as authLogout
 } from '@/lib/auth';
 
 interface AuthContextType extends AuthState {
   login: (credentials: LoginCredentials) => Promise<boolean>;
-  logout: () => void;
-  checkAuth: () => void;
+  logout: () => Promise<void>;
+  checkAuth: () => Promise<void>;
 }
 
 const AuthContext = createContext<AuthContextType | undefined>(undefined);
@@ -30,19 +29,15 @@ export function AuthProvider({ children }: AuthProviderProps) {
     error: null
   });
 
-  // Check authentication status on mount and refresh
-  const checkAuth = () => {
-    setAuthState(prev => ({ ...prev, isLoading: true, error: null }));
-
+  // Check the session with the backend on mount and refresh. Re-checks keep
+  // the current state until the answer arrives so protected pages stay mounted.
+  const checkAuth = async () => {
     try {
-      const authenticated = isAuthenticated();
-      const user = getCurrentUser();
+      const user = await fetchCurrentUser();
 
-      if (authenticated && user) {
-        // Try to refresh session
-        const refreshed = refreshSession();
+      if (user) {
         setAuthState({
-          user: refreshed ? refreshed.user : user,
+          user,
           isAuthenticated: true,
           isLoading: false,
           error: null
@@ -56,12 +51,14 @@ export function AuthProvider({ children }: AuthProviderProps) {
         });
       }
     } catch (error) {
+      // The backend is unreachable; fall back to the cached session
       console.error('Auth check failed:', error);
+      const user = getCurrentUser();
       setAuthState({
-        user: null,
-        isAuthenticated: false,
+        user,
+        isAuthenticated: user !== null,
         isLoading: false,
-        error: 'Authentication check failed'
+        error: user ? null : 'Authentication check failed'
       });
     }
   };
@@ -71,7 +68,7 @@ export function AuthProvider({ children }: AuthProviderProps) {
     setAuthState(prev => ({ ...prev, isLoading: true, error: null }));
 
     try {
-      const result = authenticate(credentials);
+      const result = await authenticate(credentials);
       
       if (result) {
         setAuthState({
@@ -101,9 +98,9 @@ export function AuthProvider({ children }: AuthProviderProps) {
   };
 
   // Logout function
-  const logout = () => {
+  const logout = async () => {
     try {
-      authLogout();
+      await authLogout();
       setAuthState({
         user: null,
         isAuthenticated: false,
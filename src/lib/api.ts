@@ -24,12 +24,31 @@ export interface PaginatedResponse<T = any> {
   };
 }
 
+// Frame Template Types (dimensions in centimetres)
+export interface FrameTemplate {
+  id: number;
+  name: string;
+  width_cm: number;
+  height_cm: number;
+  depth_cm: number;
+  max_layers: number;
+  wall_material: string;
+  led_mounts: { x: number; y: number; z: number }[];
+  created_at: string;
+  updated_at: string;
+}
+
+export type FrameTemplateRequest = Omit<FrameTemplate, 'id' | 'created_at' | 'updated_at'>;
+
 // Project Types
 export interface Project {
   id: number;
   title: string;
-  frame_size: '20x20' | '20x30';
+  frame_template_id: number;
+  frame_template?: FrameTemplate;
+  frame_size: string;
   project_data: any;
+  is_template: boolean;
   created_at: string;
   updated_at: string;
   assets?: Asset[];
@@ -37,15 +56,19 @@ export interface Project {
   shared_links?: SharedLink[];
 }
 
+// Either frame_template_id or a "<width>x<height>" frame_size is required
 export interface ProjectCreateRequest {
   title: string;
-  frame_size: '20x20' | '20x30';
+  frame_template_id?: number;
+  frame_size?: string;
+  template_id?: number;
   project_data?: any;
 }
 
 export interface ProjectUpdateRequest {
   title?: string;
-  frame_size?: '20x20' | '20x30';
+  frame_template_id?: number;
+  frame_size?: string;
   project_data?: any;
 }
 
@@ -75,11 +98,134 @@ export interface SharedLink {
   project_id: number;
   token: string;
   expires_at?: string;
+  show_quote: boolean;
+  created_at: string;
+}
+
+// Quote Types (amounts in the price table currency)
+export interface QuoteLineItem {
+  key: string;
+  name: string;
+  unit: string;
+  quantity: number;
+  unit_price: number;
+  total: number;
+  note?: string;
+}
+
+export interface Quote {
+  currency: string;
+  items: QuoteLineItem[];
+  total: number;
+  layers: number;
+  skipped_objects: number;
+  sheets?: {
+    size: string;
+    count: number;
+    utilisation: number;
+    oversized_layers: number;
+  };
+}
+
+// Cut Sheet Types (lengths in centimetres from the sheet's top-left corner)
+export interface CutSheetPlacement {
+  order: number;
+  file: string;
+  object_id: string;
+  layer: number;
+  x_cm: number;
+  y_cm: number;
+  width_cm: number;
+  height_cm: number;
+  rotated: boolean;
+}
+
+export interface CutSheetLayout {
+  sheet: { name: string; width_cm: number; height_cm: number };
+  margin_cm: number;
+  bleed_cm: number;
+  sheet_count: number;
+  utilisation: number;
+  sheets: { number: number; placements: CutSheetPlacement[]; utilisation: number }[];
+  unplaced: { order: number; object_id: string; layer: number; width_cm: number; height_cm: number }[];
+}
+
+// Cut line tracing options (lengths in centimetres)
+export interface CutlineOptions {
+  offset_cm?: number;
+  kerf_cm?: number;
+  tolerance_cm?: number;
+  threshold?: number;
+  scale?: number;
+}
+
+export interface CutSheetOptions {
+  size?: 'a4' | 'a3' | 'letter' | 'custom';
+  width_cm?: number;
+  height_cm?: number;
+  margin_cm?: number;
+  bleed_cm?: number;
+  rotate?: boolean;
+}
+
+export interface PriceRate {
+  id: number;
+  key: string;
+  name: string;
+  unit: string;
+  unit_price: number;
   created_at: string;
+  updated_at: string;
+}
+
+// Buyer view types (sanitized project served through a shared link).
+// Asset and object IDs are opaque references scoped to the link.
+export interface BuyerRendition {
+  size: number;
+  width: number;
+  height: number;
+  url: string;
+}
+
+export interface BuyerAsset {
+  id: string;
+  width: number;
+  height: number;
+  has_alpha: boolean;
+  url: string;
+  thumbnail_url: string;
+  renditions?: BuyerRendition[];
+}
+
+export interface BuyerProject {
+  title: string;
+  frame_size: string;
+  frame?: Pick<FrameTemplate, 'name' | 'width_cm' | 'height_cm' | 'depth_cm' | 'wall_material' | 'led_mounts'>;
+  revision: number;
+  scene: any;
+  assets: BuyerAsset[];
+  quote?: Quote;
+  updated_at: string;
+}
+
+export interface BuyerLink {
+  expires_at?: string;
+  views_remaining?: number;
+  approval_status: 'pending' | 'changes_requested' | 'approved';
+  approved_revision?: number;
+  decided_at?: string;
 }
 
 export interface SharedLinkCreateRequest {
   expires_at?: string;
+  show_quote?: boolean;
+}
+
+export interface SharedLinkDecisionRequest {
+  decision: 'approved' | 'changes_requested';
+  name: string;
+  note?: string;
+  revision: number;
 }
 
 // Health Check Types
@@ -106,12 +252,14 @@ class APIClient {
   ): Promise<T> {
     const url = `${this.baseURL}${endpoint}`;
     
+    // The session cookie set by /auth/login authenticates every request
     const config: RequestInit = {
+      ...options,
+      credentials: 'include',
       headers: {
         'Content-Type': 'application/json',
         ...options.headers,
       },
-      ...options,
     };
 
     try {
@@ -139,8 +287,8 @@ class APIClient {
   }
 
   // Project Methods
-  async getProjects(page = 1, limit = 10): Promise<PaginatedResponse<Project[]>> {
-    return this.request(`/projects?page=${page}&limit=${limit}`);
+  async getProjects(page = 1, limit = 10, templates = false): Promise<PaginatedResponse<Project[]>> {
+    return this.request(`/projects?page=${page}&limit=${limit}${templates ? '&templates=true' : ''}`);
   }
 
   async getProject(id: number): Promise<APIResponse<Project>> {
@@ -161,12 +309,121 @@ class APIClient {
     });
   }
 
+  async duplicateProject(id: number, title?: string): Promise<APIResponse<Project>> {
+    return this.request(`/projects/${id}/duplicate`, {
+      method: 'POST',
+      body: JSON.stringify(title ? { title } : {}),
+    });
+  }
+
+  async saveProjectAsTemplate(id: number, title?: string): Promise<APIResponse<Project>> {
+    return this.request(`/projects/${id}/as-template`, {
+      method: 'POST',
+      body: JSON.stringify(title ? { title } : {}),
+    });
+  }
+
+  getProjectExportURL(id: number): string {
+    return `${this.baseURL}/projects/${id}/export`;
+  }
+
+  getProjectGLBURL(id: number): string {
+    return `${this.baseURL}/projects/${id}/export.glb`;
+  }
+
+  getProductionExportURL(id: number, dpi?: number): string {
+    const query = dpi ? `?dpi=${dpi}` : '';
+    return `${this.baseURL}/projects/${id}/production-export${query}`;
+  }
+
+  async importProject(archive: File, title?: string): Promise<APIResponse<Project>> {
+    const formData = new FormData();
+    formData.append('file', archive);
+    if (title) {
+      formData.append('title', title);
+    }
+
+    const response = await fetch(`${this.baseURL}/projects/import`, {
+      method: 'POST',
+      credentials: 'include',
+      body: formData,
+    });
+    const data = await response.json();
+
+    if (!response.ok) {
+      throw new Error(data.error || data.message || 'Import failed');
+    }
+
+    return data;
+  }
+
   async deleteProject(id: number): Promise<APIResponse<void>> {
     return this.request(`/projects/${id}`, {
       method: 'DELETE',
     });
   }
 
+  async getProjectQuote(id: number): Promise<APIResponse<Quote>> {
+    return this.request(`/projects/${id}/quote`);
+  }
+
+  async getCutSheets(id: number, options: CutSheetOptions = {}): Promise<APIResponse<CutSheetLayout>> {
+    return this.request(`/projects/${id}/cut-sheets${this.cutSheetQuery(options)}`);
+  }
+
+  getCutSheetURL(id: number, format: 'svg' | 'pdf', options: CutSheetOptions = {}, page?: number): string {
+    const query = this.cutSheetQuery(options, { format, ...(page ? { page: String(page) } : {}) });
+    return `${this.baseURL}/projects/${id}/cut-sheets${query}`;
+  }
+
+  private cutSheetQuery(options: CutSheetOptions, extra: Record<string, string> = {}): string {
+    const params = new URLSearchParams(extra);
+    Object.entries(options).forEach(([key, value]) => {
+      if (value !== undefined) {
+        params.set(key, String(value));
+      }
+    });
+    const query = params.toString();
+    return query ? `?${query}` : '';
+  }
+
+  // Frame Template Methods
+  async getFrameTemplates(): Promise<APIResponse<FrameTemplate[]>> {
+    return this.request('/frame-templates');
+  }
+
+  async createFrameTemplate(template: FrameTemplateRequest): Promise<APIResponse<FrameTemplate>> {
+    return this.request('/frame-templates', {
+      method: 'POST',
+      body: JSON.stringify(template),
+    });
+  }
+
+  async updateFrameTemplate(id: number, updates: Partial<FrameTemplateRequest>): Promise<APIResponse<FrameTemplate>> {
+    return this.request(`/frame-templates/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(updates),
+    });
+  }
+
+  async deleteFrameTemplate(id: number): Promise<APIResponse<void>> {
+    return this.request(`/frame-templates/${id}`, {
+      method: 'DELETE',
+    });
+  }
+
+  // Price Rate Methods
+  async getPriceRates(): Promise<APIResponse<{ currency: string; rates: PriceRate[] }>> {
+    return this.request('/price-rates');
+  }
+
+  async updatePriceRate(key: string, unitPrice: number, name?: string): Promise<APIResponse<PriceRate>> {
+    return this.request(`/price-rates/${key}`, {
+      method: 'PUT',
+      body: JSON.stringify({ unit_price: unitPrice, name }),
+    });
+  }
+
   // Asset Methods
   async getProjectAssets(projectId: number): Promise<APIResponse<Asset[]>> {
     return this.request(`/projects/${projectId}/assets`);
@@ -181,6 +438,7 @@ class APIClient {
     try {
       const response = await fetch(url, {
         method: 'POST',
+        credentials: 'include',
         body: formData,
       });
       
@@ -207,6 +465,17 @@ class APIClient {
     return `${this.baseURL}/assets/${filePath}`;
   }
 
+  getCutlineURL(assetId: number, format: 'svg' | 'dxf', options: CutlineOptions = {}): string {
+    const params = new URLSearchParams();
+    Object.entries(options).forEach(([key, value]) => {
+      if (value !== undefined) {
+        params.set(key, String(value));
+      }
+    });
+    const query = params.toString();
+    return `${this.baseURL}/assets/${assetId}/cutline.${format}${query ? `?${query}` : ''}`;
+  }
+
   // Shared Link Methods
   async createSharedLink(
     projectId: number,
@@ -229,12 +498,22 @@ class APIClient {
   }
 
   async getSharedProject(token: string): Promise<APIResponse<{
-    project: Project;
-    shared_link: SharedLink;
+    project: BuyerProject;
+    shared_link: BuyerLink;
     is_shared: boolean;
   }>> {
     return this.request(`/shared/${token}`);
   }
+
+  async decideSharedProject(
+    token: string,
+    request: SharedLinkDecisionRequest
+  ): Promise<APIResponse<BuyerLink>> {
+    return this.request(`/shared/${token}/decision`, {
+      method: 'POST',
+      body: JSON.stringify(request),
+    });
+  }
 }
 
 // Create default API client instance
@@ -244,8 +523,8 @@ export const api = new APIClient();
 export { APIClient };
 
 // Utility Functions
-export function isValidFrameSize(size: string): size is '20x20' | '20x30' {
-  return size === '20x20' || size === '20x30';
+export function isValidFrameSize(size: string): boolean {
+  return /^\d+(\.\d+)?x\d+(\.\d+)?$/.test(size);
 }
 
 export function formatProjectData(data: any): string {
@@ -31,7 +31,9 @@ export class AssetService {
       return assets.map(asset => ({
         ...asset,
         url: `${API_BASE}/api/assets/${asset.file_path}`,
-        thumbnail: `${API_BASE}/api/assets/${asset.file_path}`, // For now, use same URL
+        thumbnail: `${API_BASE}${asset.thumbnail_url || `/api/assets/${asset.file_path}`}`,
+        dimensions: asset.width && asset.height ? { width: asset.width, height: asset.height } : undefined,
+        hasTransparency: asset.has_alpha,
         isSelected: false,
       }));
     } catch (error) {
@@ -83,7 +85,11 @@ export class AssetService {
                 const asset = {
                   ...data.data,
                   url: `${API_BASE}/api/assets/${data.data.file_path}`,
-                  thumbnail: `${API_BASE}/api/assets/${data.data.file_path}`,
+                  thumbnail: `${API_BASE}${data.data.thumbnail_url || `/api/assets/${data.data.file_path}`}`,
+                  dimensions: data.data.width && data.data.height
+                    ? { width: data.data.width, height: data.data.height }
+                    : undefined,
+                  hasTransparency: data.data.has_alpha,
                   size: file.size,
                   isSelected: false,
                 };
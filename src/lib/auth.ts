@@ -1,70 +1,89 @@
 import { LoginCredentials, User, AuthResponse, Session } from '@/types';
 
-// Hardcoded credentials for MVP (US-01 requirement)
-const PREDEFINED_CREDENTIALS = {
-  email: "admin@scrapyuk.com",
-  password: "scrapyuk2024"
-} as const;
-
-// Mock user data
-const MOCK_USER: User = {
-  id: "user-1",
-  email: "admin@scrapyuk.com",
-  name: "ScrapYuk Admin",
-  createdAt: new Date().toISOString()
-};
+const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api';
 
-// Session storage keys
+// Session storage keys. The session token itself lives in the HttpOnly cookie
+// set by the backend; only the signed-in user is cached here.
 const SESSION_KEYS = {
-  TOKEN: 'scrapyuk_token',
   USER: 'scrapyuk_user',
   EXPIRES_AT: 'scrapyuk_expires_at'
 } as const;
 
-// Session duration: 24 hours
-const SESSION_DURATION = 24 * 60 * 60 * 1000;
+// Creator account as returned by the backend
+interface APIUser {
+  id: number;
+  email: string;
+  name: string;
+  role: 'creator' | 'admin';
+  created_at: string;
+}
 
-/**
- * Validates login credentials against hardcoded values
- */
-export function validateCredentials(credentials: LoginCredentials): boolean {
-  return (
-    credentials.email === PREDEFINED_CREDENTIALS.email &&
-    credentials.password === PREDEFINED_CREDENTIALS.password
-  );
+function toUser(user: APIUser): User {
+  return {
+    id: String(user.id),
+    email: user.email,
+    name: user.name,
+    role: user.role,
+    createdAt: user.created_at
+  };
 }
 
 /**
- * Authenticates user with credentials and creates session
+ * Logs in through the backend, which sets the session cookie. Returns null
+ * when the credentials are rejected.
  */
-export function authenticate(credentials: LoginCredentials): AuthResponse | null {
-  if (!validateCredentials(credentials)) {
+export async function authenticate(credentials: LoginCredentials): Promise<AuthResponse | null> {
+  const response = await fetch(`${API_BASE_URL}/auth/login`, {
+    method: 'POST',
+    credentials: 'include',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify(credentials),
+  });
+  const data = await response.json();
+
+  if (response.status === 401) {
     return null;
   }
-
-  const now = new Date();
-  const expiresAt = new Date(now.getTime() + SESSION_DURATION);
-  const token = generateSessionToken();
+  if (!response.ok) {
+    throw new Error(data.error || data.message || 'Login failed');
+  }
 
   const authResponse: AuthResponse = {
-    user: MOCK_USER,
-    token,
-    expiresAt: expiresAt.toISOString()
+    user: toUser(data.data.user),
+    token: data.data.token,
+    expiresAt: data.data.expires_at
   };
 
-  // Store session in localStorage
   setSession(authResponse);
 
   return authResponse;
 }
 
 /**
- * Generates a simple session token
+ * Fetches the creator of the current session from the backend. Returns null
+ * and clears the cached session when the session is missing or has expired.
  */
-function generateSessionToken(): string {
-  const timestamp = Date.now().toString();
-  const random = Math.random().toString(36).substring(2);
-  return `sy_${timestamp}_${random}`;
+export async function fetchCurrentUser(): Promise<User | null> {
+  const response = await fetch(`${API_BASE_URL}/auth/me`, {
+    credentials: 'include',
+  });
+
+  if (response.status === 401) {
+    clearSession();
+    return null;
+  }
+  const data = await response.json();
+  if (!response.ok) {
+    throw new Error(data.error || data.message || 'Failed to fetch current user');
+  }
+
+  const user = toUser(data.data);
+  if (typeof window !== 'undefined') {
+    localStorage.setItem(SESSION_KEYS.USER, JSON.stringify(user));
+  }
+  return user;
 }
 
 /**
@@ -74,7 +93,6 @@ export function setSession(authResponse: AuthResponse): void {
   if (typeof window === 'undefined') return;
 
   try {
-    localStorage.setItem(SESSION_KEYS.TOKEN, authResponse.token);
     localStorage.setItem(SESSION_KEYS.USER, JSON.stringify(authResponse.user));
     localStorage.setItem(SESSION_KEYS.EXPIRES_AT, authResponse.expiresAt);
   } catch (error) {
@@ -89,11 +107,10 @@ export function getSession(): Session | null {
   if (typeof window === 'undefined') return null;
 
   try {
-    const token = localStorage.getItem(SESSION_KEYS.TOKEN);
     const userStr = localStorage.getItem(SESSION_KEYS.USER);
     const expiresAtStr = localStorage.getItem(SESSION_KEYS.EXPIRES_AT);
 
-    if (!token || !userStr || !expiresAtStr) {
+    if (!userStr || !expiresAtStr) {
       return null;
     }
 
@@ -103,7 +120,6 @@ export function getSession(): Session | null {
 
     return {
       user,
-      token,
       expiresAt,
       isValid
     };
@@ -129,7 +145,6 @@ export function clearSession(): void {
   if (typeof window === 'undefined') return;
 
   try {
-    localStorage.removeItem(SESSION_KEYS.TOKEN);
     localStorage.removeItem(SESSION_KEYS.USER);
     localStorage.removeItem(SESSION_KEYS.EXPIRES_AT);
   } catch (error) {
@@ -154,33 +169,18 @@ export function getCurrentUser(): User | null {
 }
 
 /**
- * Logs out user by clearing session
- */
-export function logout(): void {
-  clearSession();
-}
-
-/**
- * Refreshes session if it's still valid
+ * Logs out user by revoking the session on the backend and clearing the
+ * cached session
  */
-export function refreshSession(): AuthResponse | null {
-  const session = getSession();
-  
-  if (!session || !session.isValid) {
-    return null;
+export async function logout(): Promise<void> {
+  try {
+    await fetch(`${API_BASE_URL}/auth/logout`, {
+      method: 'POST',
+      credentials: 'include',
+    });
+  } catch (error) {
+    console.error('Failed to revoke session:', error);
+  } finally {
+    clearSession();
   }
-
-  // Extend session by another 24 hours
-  const now = new Date();
-  const expiresAt = new Date(now.getTime() + SESSION_DURATION);
-  const newToken = generateSessionToken();
-
-  const authResponse: AuthResponse = {
-    user: session.user,
-    token: newToken,
-    expiresAt: expiresAt.toISOString()
-  };
-
-  setSession(authResponse);
-  return authResponse;
-}
\ No newline at end of file
+}
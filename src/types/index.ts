@@ -5,6 +5,12 @@ export interface Asset {
   filename: string;
   file_path: string;
   uploaded_at: string;
+  width?: number;
+  height?: number;
+  has_alpha?: boolean;
+  warnings?: string[];
+  thumbnail_url?: string;
+  renditions?: AssetRendition[];
   // Frontend-specific properties
   url?: string;
   thumbnail?: string;
@@ -17,6 +23,17 @@ export interface Asset {
   isSelected?: boolean;
 }
 
+// Downscaled copy of an asset generated by the backend on upload
+export interface AssetRendition {
+  id: number;
+  asset_id: number;
+  size: number;
+  width: number;
+  height: number;
+  file_path: string;
+  url: string;
+}
+
 // Asset upload types
 export interface AssetUploadProgress {
   file: File;
@@ -134,6 +151,7 @@ export interface User {
   id: string;
   email: string;
   name: string;
+  role?: 'creator' | 'admin';
   createdAt: string;
 }
 
@@ -158,7 +176,6 @@ export interface AuthResponse {
 // Session types
 export interface Session {
   user: User;
-  token: string;
   expiresAt: string;
   isValid: boolean;
 }
\ No newline at end of file
@@ -1,4 +1,4 @@
-import { validateCredentials, authenticate, isSessionValid } from '@/lib/auth';
+import { authenticate, fetchCurrentUser, logout, isSessionValid } from '@/lib/auth';
 import { LoginCredentials } from '@/types';
 
 // Mock localStorage for testing
@@ -13,6 +13,25 @@ Object.defineProperty(window, 'localStorage', {
   value: localStorageMock
 });
 
+const fetchMock = jest.fn();
+global.fetch = fetchMock as unknown as typeof fetch;
+
+function mockResponse(status: number, body: unknown) {
+  fetchMock.mockResolvedValueOnce({
+    ok: status >= 200 && status < 300,
+    status,
+    json: async () => body,
+  });
+}
+
+const apiUser = {
+  id: 1,
+  email: 'admin@scrapyuk.com',
+  name: 'ScrapYuk Admin',
+  role: 'admin',
+  created_at: '2024-01-01T00:00:00Z'
+};
+
 describe('Authentication System', () => {
   beforeEach(() => {
     // Clear all mocks before each test
@@ -20,73 +39,90 @@ describe('Authentication System', () => {
     localStorageMock.getItem.mockReturnValue(null);
   });
 
-  describe('validateCredentials', () => {
-    it('should return true for valid predefined credentials', () => {
-      const validCredentials: LoginCredentials = {
-        email: 'admin@scrapyuk.com',
-        password: 'scrapyuk2024'
-      };
+  describe('authenticate', () => {
+    const credentials: LoginCredentials = {
+      email: 'admin@scrapyuk.com',
+      password: 'scrapyuk2024'
+    };
+
+    it('should log in through the backend and cache the user', async () => {
+      mockResponse(200, {
+        success: true,
+        data: { user: apiUser, token: 'session-token', expires_at: '2099-01-01T00:00:00Z' }
+      });
+
+      const result = await authenticate(credentials);
+
+      expect(fetchMock).toHaveBeenCalledWith(
+        expect.stringMatching(/\/auth\/login$/),
+        expect.objectContaining({ method: 'POST', credentials: 'include' })
+      );
+      expect(result?.user.id).toBe('1');
+      expect(result?.user.email).toBe('admin@scrapyuk.com');
+      expect(result?.user.role).toBe('admin');
+      expect(result?.expiresAt).toBe('2099-01-01T00:00:00Z');
+
+      // The token stays in the session cookie
+      expect(localStorageMock.setItem).toHaveBeenCalledTimes(2);
+      expect(localStorageMock.setItem).not.toHaveBeenCalledWith(expect.anything(), 'session-token');
+    });
+
+    it('should return null for rejected credentials', async () => {
+      mockResponse(401, { success: false, message: 'Invalid credentials' });
+
+      const result = await authenticate({ email: 'wrong@email.com', password: 'wrongpassword' });
 
-      expect(validateCredentials(validCredentials)).toBe(true);
+      expect(result).toBeNull();
+      expect(localStorageMock.setItem).not.toHaveBeenCalled();
     });
 
-    it('should return false for invalid email', () => {
-      const invalidCredentials: LoginCredentials = {
-        email: 'wrong@email.com',
-        password: 'scrapyuk2024'
-      };
+    it('should throw when the backend fails', async () => {
+      mockResponse(500, { success: false, message: 'Failed to create session' });
 
-      expect(validateCredentials(invalidCredentials)).toBe(false);
+      await expect(authenticate(credentials)).rejects.toThrow('Failed to create session');
     });
+  });
 
-    it('should return false for invalid password', () => {
-      const invalidCredentials: LoginCredentials = {
-        email: 'admin@scrapyuk.com',
-        password: 'wrongpassword'
-      };
+  describe('fetchCurrentUser', () => {
+    it('should return the user of the current session', async () => {
+      mockResponse(200, { success: true, data: apiUser });
 
-      expect(validateCredentials(invalidCredentials)).toBe(false);
+      const user = await fetchCurrentUser();
+
+      expect(fetchMock).toHaveBeenCalledWith(
+        expect.stringMatching(/\/auth\/me$/),
+        expect.objectContaining({ credentials: 'include' })
+      );
+      expect(user?.name).toBe('ScrapYuk Admin');
     });
 
-    it('should return false for both invalid email and password', () => {
-      const invalidCredentials: LoginCredentials = {
-        email: 'wrong@email.com',
-        password: 'wrongpassword'
-      };
+    it('should clear the cached session when signed out', async () => {
+      mockResponse(401, { success: false, message: 'Authentication required' });
 
-      expect(validateCredentials(invalidCredentials)).toBe(false);
+      expect(await fetchCurrentUser()).toBeNull();
+      expect(localStorageMock.removeItem).toHaveBeenCalledTimes(2);
     });
   });
 
-  describe('authenticate', () => {
-    it('should return AuthResponse for valid credentials', () => {
-      const validCredentials: LoginCredentials = {
-        email: 'admin@scrapyuk.com',
-        password: 'scrapyuk2024'
-      };
-
-      const result = authenticate(validCredentials);
+  describe('logout', () => {
+    it('should revoke the session and clear the cache', async () => {
+      mockResponse(200, { success: true });
 
-      expect(result).not.toBeNull();
-      expect(result?.user.email).toBe('admin@scrapyuk.com');
-      expect(result?.user.name).toBe('ScrapYuk Admin');
-      expect(result?.token).toMatch(/^sy_\d+_/);
-      expect(result?.expiresAt).toBeDefined();
+      await logout();
 
-      // Verify localStorage was called
-      expect(localStorageMock.setItem).toHaveBeenCalledTimes(3);
+      expect(fetchMock).toHaveBeenCalledWith(
+        expect.stringMatching(/\/auth\/logout$/),
+        expect.objectContaining({ method: 'POST', credentials: 'include' })
+      );
+      expect(localStorageMock.removeItem).toHaveBeenCalledTimes(2);
     });
 
-    it('should return null for invalid credentials', () => {
-      const invalidCredentials: LoginCredentials = {
-        email: 'wrong@email.com',
-        password: 'wrongpassword'
-      };
+    it('should clear the cache even when the backend is unreachable', async () => {
+      fetchMock.mockRejectedValueOnce(new Error('network down'));
 
-      const result = authenticate(invalidCredentials);
+      await logout();
 
-      expect(result).toBeNull();
-      expect(localStorageMock.setItem).not.toHaveBeenCalled();
+      expect(localStorageMock.removeItem).toHaveBeenCalledTimes(2);
     });
   });
 
@@ -101,4 +137,4 @@ describe('Authentication System', () => {
       expect(isSessionValid(pastDate.toISOString())).toBe(false);
     });
   });
-});
\ No newline at end of file
+});